@@ -0,0 +1,124 @@
+package promlinter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BaselineEntry counts the issues reported by a rule on a metric in a file.
+type BaselineEntry struct {
+	File   string `json:"file"`
+	Metric string `json:"metric"`
+	Rule   string `json:"rule"`
+	Count  int    `json:"count"`
+}
+
+// Baseline records the accepted issues, so that only the new issues are
+// reported. The entries don't depend on the lines of the issues, so they
+// survive edits of the files, and their files are relative to the directory
+// of the baseline file, so they don't depend on the paths linted.
+type Baseline struct {
+	Entries []BaselineEntry `json:"issues"`
+
+	// filename is the baseline file.
+	filename string
+}
+
+type baselineKey struct {
+	file, metric, rule string
+}
+
+// keyOf returns the key of the issue, with its file relative to the
+// directory of the baseline file.
+func (b Baseline) keyOf(iss Issue) baselineKey {
+	file := iss.Pos.Filename
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+		if dir, err := filepath.Abs(filepath.Dir(b.filename)); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				file = rel
+			}
+		}
+	}
+	return baselineKey{file: filepath.ToSlash(file), metric: iss.Metric, rule: iss.RuleID}
+}
+
+// NewBaseline returns the baseline, to be written to the file, accepting
+// the issues.
+func NewBaseline(filename string, issues []Issue) Baseline {
+	b := Baseline{filename: filename}
+	counts := map[baselineKey]int{}
+	for _, iss := range issues {
+		counts[b.keyOf(iss)]++
+	}
+
+	b.Entries = make([]BaselineEntry, 0, len(counts))
+	for k, n := range counts {
+		b.Entries = append(b.Entries, BaselineEntry{File: k.file, Metric: k.metric, Rule: k.rule, Count: n})
+	}
+	sort.Slice(b.Entries, func(i, j int) bool {
+		ei, ej := b.Entries[i], b.Entries[j]
+		if ei.File != ej.File {
+			return ei.File < ej.File
+		}
+		if ei.Metric != ej.Metric {
+			return ei.Metric < ej.Metric
+		}
+		return ei.Rule < ej.Rule
+	})
+	return b
+}
+
+// LoadBaseline reads a baseline from a JSON file written by Write.
+func LoadBaseline(filename string) (Baseline, error) {
+	b := Baseline{filename: filename}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return b, err
+	}
+
+	err = json.Unmarshal(data, &b)
+	return b, err
+}
+
+// Write writes the baseline to its JSON file.
+func (b Baseline) Write() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(b.filename, append(data, '\n'), 0o644)
+}
+
+// Filter returns the issues which are not in the baseline, and the entries
+// of the baseline which are fixed, with the number of issues fixed as Count.
+func (b Baseline) Filter(issues []Issue) ([]Issue, []BaselineEntry) {
+	accepted := map[baselineKey]int{}
+	for _, e := range b.Entries {
+		accepted[baselineKey{file: e.File, metric: e.Metric, rule: ruleID(e.Rule)}] += e.Count
+	}
+
+	var unaccepted []Issue
+	for _, iss := range issues {
+		k := b.keyOf(iss)
+		if accepted[k] > 0 {
+			accepted[k]--
+			continue
+		}
+		unaccepted = append(unaccepted, iss)
+	}
+
+	var fixed []BaselineEntry
+	for _, e := range b.Entries {
+		k := baselineKey{file: e.File, metric: e.Metric, rule: ruleID(e.Rule)}
+		if n := accepted[k]; n > 0 {
+			// Entries with the same key are reported fixed once.
+			accepted[k] = 0
+			fixed = append(fixed, BaselineEntry{File: e.File, Metric: e.Metric, Rule: e.Rule, Count: n})
+		}
+	}
+	return unaccepted, fixed
+}
@@ -0,0 +1,72 @@
+package promlinter
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	dir := t.TempDir()
+	issue := func(file string, line int, metric, rule string) Issue {
+		return Issue{Pos: token.Position{Filename: filepath.Join(dir, file), Line: line}, Metric: metric, RuleID: rule, Text: rule}
+	}
+
+	filename := filepath.Join(dir, "baseline.json")
+	b := NewBaseline(filename, []Issue{
+		issue("b.go", 3, "foo", "Help"),
+		issue("a.go", 10, "bar", "Counter"),
+		issue("b.go", 3, "foo", "Counter"),
+		issue("b.go", 4, "foo", "Counter"),
+	})
+	assert.Equal(t, []BaselineEntry{
+		{File: "a.go", Metric: "bar", Rule: "Counter", Count: 1},
+		{File: "b.go", Metric: "foo", Rule: "Counter", Count: 2},
+		{File: "b.go", Metric: "foo", Rule: "Help", Count: 1},
+	}, b.Entries)
+
+	require.NoError(t, b.Write())
+	loaded, err := LoadBaseline(filename)
+	require.NoError(t, err)
+	assert.Equal(t, b, loaded)
+
+	// The lines of the issues changed, a foo issue of Counter and the Help
+	// issue are fixed, and baz is new.
+	issues, fixed := b.Filter([]Issue{
+		issue("a.go", 12, "bar", "Counter"),
+		issue("b.go", 7, "foo", "Counter"),
+		issue("b.go", 8, "baz", "Counter"),
+	})
+	assert.Equal(t, []Issue{issue("b.go", 8, "baz", "Counter")}, issues)
+	assert.Equal(t, []BaselineEntry{
+		{File: "b.go", Metric: "foo", Rule: "Counter", Count: 1},
+		{File: "b.go", Metric: "foo", Rule: "Help", Count: 1},
+	}, fixed)
+
+	// More issues than accepted are new.
+	issues, fixed = b.Filter([]Issue{
+		issue("a.go", 10, "bar", "Counter"),
+		issue("a.go", 11, "bar", "Counter"),
+		issue("b.go", 3, "foo", "Counter"),
+		issue("b.go", 4, "foo", "Counter"),
+		issue("b.go", 3, "foo", "Help"),
+	})
+	assert.Equal(t, []Issue{issue("a.go", 11, "bar", "Counter")}, issues)
+	assert.Empty(t, fixed)
+
+	// The files are relative to the baseline file, whatever the paths linted.
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	rel, err := filepath.Rel(wd, filepath.Join(dir, "a.go"))
+	require.NoError(t, err)
+	relIssue := Issue{Pos: token.Position{Filename: rel, Line: 10}, Metric: "bar", RuleID: "Counter", Text: "Counter"}
+	issues, _ = b.Filter([]Issue{relIssue})
+	assert.Empty(t, issues)
+
+	_, err = LoadBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
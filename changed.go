@@ -0,0 +1,63 @@
+package promlinter
+
+import (
+	"go/ast"
+	"path/filepath"
+)
+
+// LineRange is a range of lines of a file, from Start to End included.
+type LineRange struct {
+	Start, End int
+}
+
+// ChangedLines are the ranges of lines added or modified, by absolute file
+// name.
+type ChangedLines map[string][]LineRange
+
+// declaration is the range of lines of a call declaring a metric.
+type declaration struct {
+	filename string
+	lines    LineRange
+}
+
+// declarationOf returns the declaration of the call.
+func (v *visitor) declarationOf(call *ast.CallExpr) declaration {
+	start, end := v.fs.Position(call.Pos()), v.fs.Position(call.End())
+	return declaration{filename: start.Filename, lines: LineRange{Start: start.Line, End: end.Line}}
+}
+
+// changed reports whether any line of the range in the file is changed.
+func (c ChangedLines) changed(filename string, lines LineRange) bool {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	for _, r := range c[filename] {
+		if r.Start <= lines.End && lines.Start <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// changedIssues returns the issues on changed lines, and on the metrics
+// with a changed declaration.
+func (v *visitor) changedIssues(c ChangedLines) []Issue {
+	changedMetrics := map[*MetricFamilyWithPos]bool{}
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		for _, d := range m.declarations {
+			if c.changed(d.filename, d.lines) {
+				changedMetrics[m] = true
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, iss := range v.issues {
+		line := LineRange{Start: iss.Pos.Line, End: iss.Pos.Line}
+		if m := v.issueMetric(iss); m != nil && changedMetrics[m] || c.changed(iss.Pos.Filename, line) {
+			issues = append(issues, iss)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,57 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanged(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "changed.go", `package foo
+
+var (
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests", Help: "Requests."})
+	_ = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "errors", Help: "Errors."},
+		[]string{"code"},
+	)
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "retries", Help: "Retries."})
+	//promlinter:ignore Counter
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_length", Help: "Length of the queue."})
+)
+`)
+	other := parseSource(t, fs, "other/other.go", `package other
+
+var _ = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests", Help: "Other requests."})
+`)
+	filename, err := filepath.Abs("changed.go")
+	require.NoError(t, err)
+	otherFilename, err := filepath.Abs(filepath.Join("other", "other.go"))
+	require.NoError(t, err)
+
+	metrics := func(c ChangedLines) []string {
+		var metrics []string
+		for _, iss := range RunLint(fs, []*ast.File{file, other}, Setting{Changed: c}) {
+			metrics = append(metrics, iss.Pos.Filename+" "+iss.Metric+" ("+iss.RuleID+")")
+		}
+		return metrics
+	}
+
+	// The label line of errors changed, and the suppression comment.
+	assert.Equal(t, []string{"changed.go errors (Counter)", "changed.go queue_length (Suppression)", "changed.go queue_length (Suppression)"}, metrics(ChangedLines{
+		filename: {{Start: 7, End: 7}, {Start: 10, End: 10}},
+	}))
+	// The requests metric of the other package, of the same name, didn't
+	// change.
+	assert.Equal(t, []string{"changed.go requests (Counter)", "changed.go retries (Counter)"}, metrics(ChangedLines{
+		filename: {{Start: 4, End: 4}, {Start: 9, End: 9}},
+	}))
+	assert.Equal(t, []string{"other/other.go requests (Counter)"}, metrics(ChangedLines{otherFilename: {{Start: 3, End: 3}}}))
+	assert.Empty(t, metrics(ChangedLines{filename: {{Start: 12, End: 20}}, otherFilename: {{Start: 1, End: 2}}}))
+	assert.Len(t, metrics(nil), 6)
+}
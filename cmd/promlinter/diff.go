@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// The kinds of metric changes, in the order they are printed.
+const (
+	changeRemoved       = "removed"
+	changeRenamed       = "renamed"
+	changeTypeChanged   = "type changed"
+	changeLabelsRemoved = "labels removed"
+	changeLabelsAdded   = "labels added"
+	changeHelpChanged   = "help changed"
+	changeNew           = "new"
+	changeDuplicated    = "duplicated"
+)
+
+var changeOrder = map[string]int{
+	changeRemoved:       0,
+	changeRenamed:       1,
+	changeTypeChanged:   2,
+	changeLabelsRemoved: 3,
+	changeLabelsAdded:   4,
+	changeHelpChanged:   5,
+	changeNew:           6,
+	changeDuplicated:    7,
+}
+
+// metricChange is a change of a metric between two inventories.
+type metricChange struct {
+	kind   string
+	metric string
+	// details describe the change, in markdown.
+	details string
+	// breaking is set if the change breaks the queries of the metric.
+	breaking bool
+}
+
+// loadInventory reads the metrics written by list -o json.
+func loadInventory(filename string) ([]MetricForPrinting, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []MetricForPrinting
+	err = json.Unmarshal(b, &metrics)
+	return metrics, err
+}
+
+// byName returns the first declaration of each metric, by name, and the
+// positions of the names declared several times with differing types,
+// labels or help.
+func byName(metrics []MetricForPrinting) (map[string]MetricForPrinting, map[string][]string) {
+	m := make(map[string]MetricForPrinting, len(metrics))
+	positions := map[string][]string{}
+	differing := map[string]bool{}
+	for _, metric := range metrics {
+		if first, ok := m[metric.Name]; !ok {
+			m[metric.Name] = metric
+		} else if !sameDefinition(first, metric) {
+			differing[metric.Name] = true
+		}
+		positions[metric.Name] = append(positions[metric.Name], fmt.Sprintf("%s:%d", metric.Filename, metric.Line))
+	}
+
+	duplicates := map[string][]string{}
+	for name := range differing {
+		duplicates[name] = positions[name]
+	}
+	return m, duplicates
+}
+
+// sameDefinition reports whether the declarations have the same type,
+// labels and help.
+func sameDefinition(a, b MetricForPrinting) bool {
+	return a.Type == b.Type && a.Help == b.Help &&
+		len(difference(a.Labels, b.Labels)) == 0 && len(difference(b.Labels, a.Labels)) == 0
+}
+
+// diffInventories returns the changes of the metrics from the old inventory
+// to the new one. A removed metric and a new one are a rename if they have
+// the same help text and either the same type or the same labels. The names
+// newly declared several times with differing definitions are reported, as
+// only their first declaration is compared.
+func diffInventories(oldMetrics, newMetrics []MetricForPrinting) []metricChange {
+	olds, oldDuplicates := byName(oldMetrics)
+	news, newDuplicates := byName(newMetrics)
+
+	var changes []metricChange
+	for name, pos := range newDuplicates {
+		if _, ok := oldDuplicates[name]; !ok {
+			changes = append(changes, metricChange{
+				kind:    changeDuplicated,
+				metric:  name,
+				details: fmt.Sprintf("declared %d times with differing definitions, the first is compared: %s", len(pos), codeList(pos)),
+			})
+		}
+	}
+	var removed, added []MetricForPrinting
+	for name, o := range olds {
+		n, ok := news[name]
+		if !ok {
+			removed = append(removed, o)
+			continue
+		}
+		changes = append(changes, compareMetrics(name, o, n)...)
+	}
+	for name, n := range news {
+		if _, ok := olds[name]; !ok {
+			added = append(added, n)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Name < removed[j].Name })
+	sort.Slice(added, func(i, j int) bool { return added[i].Name < added[j].Name })
+
+	renamed := map[string]bool{}
+	for _, o := range removed {
+		best, bestScore := -1, 0
+		for i, n := range added {
+			if renamed[n.Name] {
+				continue
+			}
+			if score := renameScore(o, n); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			changes = append(changes, metricChange{kind: changeRemoved, metric: o.Name, breaking: true})
+			continue
+		}
+
+		n := added[best]
+		renamed[n.Name] = true
+		changes = append(changes, metricChange{
+			kind:     changeRenamed,
+			metric:   o.Name,
+			details:  fmt.Sprintf("renamed to `%s`", n.Name),
+			breaking: true,
+		})
+		changes = append(changes, compareMetrics(n.Name, o, n)...)
+	}
+	for _, n := range added {
+		if !renamed[n.Name] {
+			changes = append(changes, metricChange{kind: changeNew, metric: n.Name})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].kind != changes[j].kind {
+			return changeOrder[changes[i].kind] < changeOrder[changes[j].kind]
+		}
+		return changes[i].metric < changes[j].metric
+	})
+	return changes
+}
+
+// renameScore scores how likely the new metric is the old one renamed, 0
+// if it is not.
+func renameScore(o, n MetricForPrinting) int {
+	if o.Help == "" || o.Help != n.Help {
+		return 0
+	}
+	score := 0
+	if o.Type == n.Type {
+		score++
+	}
+	if len(difference(o.Labels, n.Labels)) == 0 && len(difference(n.Labels, o.Labels)) == 0 {
+		score++
+	}
+	return score
+}
+
+// compareMetrics returns the changes of a metric kept, or renamed to name.
+func compareMetrics(name string, o, n MetricForPrinting) []metricChange {
+	var changes []metricChange
+	if o.Type != n.Type {
+		changes = append(changes, metricChange{
+			kind:     changeTypeChanged,
+			metric:   name,
+			details:  fmt.Sprintf("%s → %s", o.Type, n.Type),
+			breaking: true,
+		})
+	}
+	if labels := difference(o.Labels, n.Labels); len(labels) > 0 {
+		changes = append(changes, metricChange{
+			kind:     changeLabelsRemoved,
+			metric:   name,
+			details:  codeList(labels),
+			breaking: true,
+		})
+	}
+	if labels := difference(n.Labels, o.Labels); len(labels) > 0 {
+		changes = append(changes, metricChange{
+			kind:    changeLabelsAdded,
+			metric:  name,
+			details: codeList(labels),
+		})
+	}
+	if o.Help != n.Help {
+		changes = append(changes, metricChange{
+			kind:    changeHelpChanged,
+			metric:  name,
+			details: fmt.Sprintf("%q → %q", o.Help, n.Help),
+		})
+	}
+	return changes
+}
+
+// difference returns the elements of a which are not in b.
+func difference(a, b []string) []string {
+	var diff []string
+	for _, x := range a {
+		if !contains(b, x) && !contains(diff, x) {
+			diff = append(diff, x)
+		}
+	}
+	return diff
+}
+
+func contains(arr []string, s string) bool {
+	for _, x := range arr {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func codeList(arr []string) string {
+	codes := make([]string, len(arr))
+	for i, s := range arr {
+		codes[i] = "`" + s + "`"
+	}
+	return strings.Join(codes, ", ")
+}
+
+// breaking reports whether any of the changes is breaking.
+func breaking(changes []metricChange) bool {
+	for _, c := range changes {
+		if c.breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// tableEscaper escapes the details in a markdown table cell, which must not
+// contain pipes or line breaks.
+var tableEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// printChanges prints the changes as a markdown table, to paste in pull
+// request descriptions.
+func printChanges(w io.Writer, changes []metricChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No metric changes.")
+		return
+	}
+
+	n := 0
+	for _, c := range changes {
+		if c.breaking {
+			n++
+		}
+	}
+	fmt.Fprintf(w, "%d metric changes, %d breaking.\n\n", len(changes), n)
+
+	fmt.Fprintln(w, "| Change | Metric | Details | Breaking |")
+	fmt.Fprintln(w, "|--------|--------|---------|----------|")
+	for _, c := range changes {
+		b := "no"
+		if c.breaking {
+			b = "**yes**"
+		}
+		fmt.Fprintf(w, "| %s | `%s` | %s | %s |\n", c.kind, c.metric, tableEscaper.Replace(c.details), b)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffInventories(t *testing.T) {
+	oldMetrics := []MetricForPrinting{
+		{Name: "requests_total", Type: "COUNTER", Help: "Requests.", Labels: []string{"code", "method"}},
+		{Name: "queue_length", Type: "GAUGE", Help: "Length of the queue."},
+		{Name: "http_latency_seconds", Type: "HISTOGRAM", Help: "Latency of the requests.", Labels: []string{"handler"}},
+		{Name: "cache_hits", Type: "COUNTER", Help: "Cache hits."},
+		{Name: "build_info", Type: "GAUGE", Help: "Build information.", Labels: []string{"version"}},
+	}
+	newMetrics := []MetricForPrinting{
+		{Name: "requests_total", Type: "COUNTER", Help: "Requests served.", Labels: []string{"code", "handler"}},
+		{Name: "queue_length", Type: "COUNTER", Help: "Length of the queue."},
+		{Name: "http_request_duration_seconds", Type: "HISTOGRAM", Help: "Latency of the requests.", Labels: []string{"handler"}},
+		{Name: "build_info", Type: "GAUGE", Help: "Build information.", Labels: []string{"version"}},
+		{Name: "cache_misses_total", Type: "COUNTER", Help: "Cache misses."},
+	}
+
+	changes := diffInventories(oldMetrics, newMetrics)
+	assert.Equal(t, []metricChange{
+		{kind: changeRemoved, metric: "cache_hits", breaking: true},
+		{kind: changeRenamed, metric: "http_latency_seconds", details: "renamed to `http_request_duration_seconds`", breaking: true},
+		{kind: changeTypeChanged, metric: "queue_length", details: "GAUGE → COUNTER", breaking: true},
+		{kind: changeLabelsRemoved, metric: "requests_total", details: "`method`", breaking: true},
+		{kind: changeLabelsAdded, metric: "requests_total", details: "`handler`"},
+		{kind: changeHelpChanged, metric: "requests_total", details: `"Requests." → "Requests served."`},
+		{kind: changeNew, metric: "cache_misses_total"},
+	}, changes)
+	assert.True(t, breaking(changes))
+
+	var b bytes.Buffer
+	printChanges(&b, changes[4:])
+	assert.Equal(t, "3 metric changes, 0 breaking.\n\n"+
+		"| Change | Metric | Details | Breaking |\n"+
+		"|--------|--------|---------|----------|\n"+
+		"| labels added | `requests_total` | `handler` | no |\n"+
+		"| help changed | `requests_total` | \"Requests.\" → \"Requests served.\" | no |\n"+
+		"| new | `cache_misses_total` |  | no |\n", b.String())
+	assert.False(t, breaking(changes[4:]))
+
+	b.Reset()
+	printChanges(&b, diffInventories(oldMetrics, oldMetrics))
+	assert.Equal(t, "No metric changes.\n", b.String())
+}
+
+func TestDiffDuplicates(t *testing.T) {
+	oldMetrics := []MetricForPrinting{
+		{Name: "requests_total", Type: "COUNTER", Help: "Requests.", Filename: "a.go", Line: 3},
+	}
+	newMetrics := []MetricForPrinting{
+		{Name: "requests_total", Type: "COUNTER", Help: "Requests.", Filename: "a.go", Line: 3},
+		{Name: "requests_total", Type: "GAUGE", Help: "Requests\nin flight.", Filename: "b.go", Line: 7},
+		{Name: "up", Type: "GAUGE", Help: "Up.", Filename: "a.go", Line: 5},
+		{Name: "up", Type: "GAUGE", Help: "Up.", Filename: "b.go", Line: 9},
+	}
+
+	changes := diffInventories(oldMetrics, newMetrics)
+	assert.Equal(t, []metricChange{{
+		kind:   changeNew,
+		metric: "up",
+	}, {
+		kind:    changeDuplicated,
+		metric:  "requests_total",
+		details: "declared 2 times with differing definitions, the first is compared: `a.go:3`, `b.go:7`",
+	}}, changes)
+
+	// The duplicates of the old inventory aren't new.
+	var b bytes.Buffer
+	printChanges(&b, diffInventories(newMetrics, newMetrics))
+	assert.Equal(t, "No metric changes.\n", b.String())
+
+	b.Reset()
+	printChanges(&b, []metricChange{{kind: changeHelpChanged, metric: "up", details: "Up|down.\nOr\r\nnot."}})
+	assert.Equal(t, "1 metric changes, 0 breaking.\n\n"+
+		"| Change | Metric | Details | Breaking |\n"+
+		"|--------|--------|---------|----------|\n"+
+		"| help changed | `up` | Up\\|down.<br>Or<br>not. | no |\n", b.String())
+}
+
+func TestLoadInventory(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "metrics.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`[
+  {"Name": "requests_total", "Help": "Requests.", "Type": "COUNTER", "Filename": "main.go", "Labels": ["code"], "Line": 10, "Column": 2}
+]`), 0o644))
+
+	metrics, err := loadInventory(filename)
+	require.NoError(t, err)
+	assert.Equal(t, []MetricForPrinting{{
+		Name: "requests_total", Help: "Requests.", Type: "COUNTER", Filename: "main.go", Labels: []string{"code"}, Line: 10, Column: 2,
+	}}, metrics)
+}
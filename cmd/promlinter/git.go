@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yeya24/promlinter"
+)
+
+// git runs a git command in the current directory and returns its output.
+func git(args ...string) ([]byte, error) {
+	out, err := exec.Command("git", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, bytes.TrimSpace(exitErr.Stderr))
+	}
+	return out, err
+}
+
+// changedLines returns the lines added or modified in the working tree since
+// the revision, all the lines of the untracked files being added.
+func changedLines(rev string) (promlinter.ChangedLines, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := git("-c", "core.quotePath=false", "diff", "-U0", "--no-color", "--no-ext-diff", "--no-prefix", "--relative", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	changed := parseDiff(diff, dir)
+
+	untracked, err := git("ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range strings.Split(string(untracked), "\x00") {
+		if f != "" {
+			changed[filepath.Join(dir, f)] = []promlinter.LineRange{{Start: 1, End: math.MaxInt32}}
+		}
+	}
+	return changed, nil
+}
+
+var hunkRE = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parseDiff returns the lines added or modified by a diff with no context
+// and no path prefix, by absolute file name. The line before the lines
+// deleted is modified.
+func parseDiff(diff []byte, dir string) promlinter.ChangedLines {
+	changed := promlinter.ChangedLines{}
+	filename := ""
+	// The lines are read whole, whatever their length, e.g. of generated
+	// files.
+	r := bufio.NewReader(bytes.NewReader(diff))
+	for {
+		line, err := r.ReadString('\n')
+		if line == "" && err != nil {
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, "+++ ") {
+			filename = ""
+			if name := strings.TrimPrefix(line, "+++ "); name != "/dev/null" {
+				filename = filepath.Join(dir, name)
+			}
+			continue
+		}
+
+		m := hunkRE.FindStringSubmatch(line)
+		if m == nil || filename == "" {
+			continue
+		}
+		start, _ := strconv.Atoi(m[1])
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2])
+		}
+		end := start + count - 1
+		if count == 0 {
+			end = start
+		}
+		changed[filename] = append(changed[filename], promlinter.LineRange{Start: start, End: end})
+	}
+	return changed
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yeya24/promlinter"
+)
+
+func TestParseDiff(t *testing.T) {
+	dir := filepath.FromSlash("/src/repo")
+	diff := `diff --git pkg/metrics.go pkg/metrics.go
+index 1111111..2222222 100644
+--- pkg/metrics.go
++++ pkg/metrics.go
+@@ -4 +4 @@ var (
+-	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests"})
++	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total"})
+@@ -10,0 +11,3 @@ var (
++	_ = prometheus.NewCounter(prometheus.CounterOpts{
++		Name: "errors_total",
++	})
+@@ -20,2 +23,0 @@ func update() {
+-	foo.Inc()
+-	bar.Inc()
+diff --git old.go old.go
+deleted file mode 100644
+index 3333333..0000000
+--- old.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package foo
+-
+-var x = 1
+`
+
+	assert.Equal(t, promlinter.ChangedLines{
+		filepath.Join(dir, "pkg", "metrics.go"): {{Start: 4, End: 4}, {Start: 11, End: 13}, {Start: 23, End: 23}},
+	}, parseDiff([]byte(diff), dir))
+
+	// The changes after a long line, e.g. of a generated file, are kept.
+	long := `diff --git gen.go gen.go
+--- gen.go
++++ gen.go
+@@ -1 +1 @@
+-var data = ""
++var data = "` + strings.Repeat("x", 2*1024*1024) + `"
+` + diff
+	changed := parseDiff([]byte(long), dir)
+	assert.Equal(t, []promlinter.LineRange{{Start: 1, End: 1}}, changed[filepath.Join(dir, "gen.go")])
+	assert.Len(t, changed[filepath.Join(dir, "pkg", "metrics.go")], 3)
+}
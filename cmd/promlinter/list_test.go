@@ -11,10 +11,46 @@ import (
 func TestLabel(t *testing.T) {
 	fs := token.NewFileSet()
 
-	metrics := promlinter.RunList(fs, findFiles([]string{"../../testdata/"}, fs), true)
+	metrics := promlinter.RunList(fs, findFiles(workTree{}, []string{"../../testdata/"}, fs), true)
 
 	assert.Equal(t, 11, len(metrics))
 	assert.Equal(t, []string{"namespace", "name"}, metrics[8].Labels())
 	assert.Equal(t, []string{"namespace", "name", "const-label1=value1", "const-label2=?"}, metrics[9].Labels())
 	assert.Equal(t, []string{"namespace", "name"}, metrics[10].Labels())
 }
+
+func TestPositions(t *testing.T) {
+	fs := token.NewFileSet()
+
+	metrics := promlinter.RunList(fs, findFiles(workTree{}, []string{"../../testdata/"}, fs), true)
+
+	var declaredTwice []promlinter.MetricFamilyWithPos
+	for _, m := range metrics {
+		if len(m.Positions) > 1 {
+			declaredTwice = append(declaredTwice, m)
+		}
+	}
+
+	if assert.Len(t, declaredTwice, 1) {
+		m := declaredTwice[0]
+		assert.Equal(t, "test_metric_total", *m.MetricFamily.Name)
+		assert.Equal(t, m.Positions[0], m.Pos)
+
+		p := printer{addPosition: true}
+		assert.Equal(t, "../../testdata/testdata.go:48:3,../../testdata/testdata.go:57:3", p.pos(m.Positions))
+
+		p = printer{addModule: true}
+		assert.Equal(t, "../../testdata", p.pos(m.Positions))
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	errors := []promlinter.Issue{{Severity: promlinter.SeverityInfo}, {Severity: promlinter.SeverityError}}
+	warnings := []promlinter.Issue{{Severity: promlinter.SeverityInfo}, {Severity: promlinter.SeverityWarning}}
+
+	assert.Equal(t, exitClean, exitCode(nil, true))
+	assert.Equal(t, exitIssues, exitCode(errors, false))
+	assert.Equal(t, exitClean, exitCode(warnings, false))
+	assert.Equal(t, exitIssues, exitCode(warnings, true))
+	assert.Equal(t, exitClean, exitCode([]promlinter.Issue{{Severity: promlinter.SeverityInfo}}, true))
+}
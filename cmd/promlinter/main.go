@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
@@ -41,6 +42,136 @@ It is also supported to disable the lint functions using repeated flag --disable
 	[CamelCase]: CamelCase detects metric names and label names written in camelCase.
 
 	[UnitAbbreviations]: UnitAbbreviations detects abbreviated units in the metric name.
+
+	[Exemplar]: Exemplar detects invalid or oversized exemplar labels and exemplars attached to metric types which don't support them.
+
+	[WillPanic]: WillPanic detects metrics which make client_golang panic when created or registered, e.g. invalid names, duplicate labels or unsorted buckets.
+
+	[OpenMetrics]: OpenMetrics detects metrics breaking the OpenMetrics conventions, e.g. counters without the _total suffix if the Counter rule is disabled, reserved suffixes, units which are not base units or not the suffix of the name, info metrics or statesets with a unit, gauge histograms with the _total suffix, and statesets with a le label or without a label named after the metric. It only runs with flag --openmetrics.
+
+	client_golang can't declare gauge histograms, and the labels of the kube-state-metrics statesets are only known at runtime, so the checks of these types only apply to the metrics whose type and labels are known statically.
+
+	[Escaping]: Escaping detects metric names, and label names of a metric, which collide once escaped with the underscores, dots or values scheme. It only runs with flag --name-validation=utf8.
+
+	[Parsing]: Parsing detects metric options which can't be parsed statically. It only runs with flag --strict.
+
+	[Suppression]: Suppression detects //promlinter:ignore comments which are unused, name unknown rules or have no reason, e.g. //promlinter:ignore Counter reason="legacy dashboards".
+
+	lintUnitAbbreviations is still accepted for UnitAbbreviations.
+
+Optional lint functions only run when enabled using repeated flag --enable. Current supported functions are:
+
+	[GlobalRegisterer]: GlobalRegisterer detects library packages registering metrics on the global prometheus.DefaultRegisterer.
+
+	[StaleSeries]: StaleSeries detects vectors with labels identifying transient entities whose series are never deleted.
+
+	[Unregistered]: Unregistered detects metrics which are never registered, and registries which are never exposed via promhttp.HandlerFor or a push client.
+
+	[Unused]: Unused detects metrics which are never updated, except Func and const metrics.
+
+	[Conflict]: Conflict detects metrics declared several times with a different type, label set or help text.
+
+	[NearDuplicate]: NearDuplicate detects metric names which are likely accidental duplicates, e.g. differing by plural, a synonym, a typo or a token inserted or dropped.
+
+	[LabelVocabulary]: LabelVocabulary detects label names which are forbidden synonyms in the vocabulary, or written in different forms across metrics.
+
+	[Namespace]: Namespace detects metrics breaking the namespace policy of their package, by default the metrics of a directory must share a namespace.
+
+	[HelpCapitalized]: HelpCapitalized detects help texts not starting with a capital letter.
+
+	[HelpPeriod]: HelpPeriod detects help texts not ending like the others, with or without a period.
+
+	[HelpMinLength]: HelpMinLength detects help texts shorter than the minimum length.
+
+	[HelpRepeatsName]: HelpRepeatsName detects help texts only repeating the metric name.
+
+	[HelpPlaceholder]: HelpPlaceholder detects help texts containing placeholders like TODO or FIXME.
+
+	[HelpEmpty]: HelpEmpty detects empty help texts.
+
+	[HelpDuplicate]: HelpDuplicate detects distinct metrics with identical help texts.
+
+	[HelpUnit]: HelpUnit detects help texts mentioning a unit which contradicts the unit suffix of the metric name, e.g. milliseconds for *_seconds.
+
+	[UnitPolicy]: UnitPolicy detects metric names whose unit suffix breaks the unit policy, and ratios set outside of 0-1.
+
+	[BestPractices]: BestPractices detects names breaking the naming best practices, e.g. *_per_second gauges, *_info metrics which are not gauges or OpenMetrics infos set to 1, *_timestamp_seconds metrics which are not gauges, *_created metrics, label-like segments like *_for_user_x and trailing numbers.
+
+	[LabelReserved]: LabelReserved detects label names starting with "__", which is reserved for internal use.
+
+	[LabelCharset]: LabelCharset detects label names with characters invalid under the legacy name scheme.
+
+	[LabelTypeCollision]: LabelTypeCollision detects le labels on metrics other than histograms, and quantile labels on metrics other than summaries.
+
+	[LabelCount]: LabelCount detects metrics with more labels than the maximum.
+
+	[LabelDeny]: LabelDeny detects denied label names, e.g. with unbounded or sensitive values.
+
+	[LabelRequired]: LabelRequired detects metrics missing the labels required by their package.
+
+	[EscapedNames]: EscapedNames detects metric and label names which are not valid legacy names, and so are escaped differently by each escaping scheme.
+
+The rules can be suppressed for a metric with a comment on the line of its Opts or NewDesc call, or on the line above, e.g. //promlinter:ignore Counter,Help reason="legacy dashboards".
+
+Each issue is printed with its severity and the ID of the rule reporting it, the rules are documented in docs/rules.md.
+
+The severity of a rule, error, warning or info, can be set using repeated flag --severity, e.g. --severity Help=warning. The exit code is 0 if there are no errors, 1 if there are errors, or warnings with flag --fail-on-warning, and 2 if the linter fails.
+
+An existing codebase can adopt the linter gradually: flag --write-baseline FILE records the current issues, by metric, rule and file relative to the baseline file but not line, then with flag --baseline FILE only the new issues are reported, along with the baseline issues which are fixed.
+
+For pull requests, flag --new-from-rev REV only reports the issues on metrics whose declaration lines were added or modified since the git revision, e.g. --new-from-rev origin/main.
+
+Command list reads the files stored at a git revision with flag --rev, without checking it out, e.g. list --rev v1.4.0 -o json ./... > old.json, to compare the last release with HEAD.
+
+Command diff compares two metric inventories written by list -o json, e.g. of the last release and HEAD, and prints the changes as markdown: removed, renamed, new metrics, type, label and help changes, and the metric names newly declared several times with differing definitions, whose first declaration is compared. It exits with code 1 if any change is breaking, i.e. a removal, rename, type change or label removal.
+
+The lint settings can also be read from a YAML file using flag --config, e.g.
+
+	strict: false
+	openmetrics: false
+	# Validation scheme of the names, legacy or utf8.
+	nameValidation: legacy
+	disable: [Help]
+	enable: [GlobalRegisterer]
+	libraries:
+	  # Packages which are libraries, by default all the packages not named main.
+	  paths: ["pkg/..."]
+	  exclude: ["pkg/cmd/..."]
+	# Patterns of label names identifying transient entities.
+	entityLabels: ["pod", "*_id"]
+	# Groups of metric name tokens meaning the same.
+	synonyms: [[duration, latency], [request, req]]
+	# Canonical label names and their forbidden synonyms.
+	labelVocabulary:
+	  code: [status, status_code, http_code]
+	# Namespace policies, the first one applying to a package is used.
+	namespaces:
+	  - path: "pkg/store/..."
+	    namespace: store
+	  - package: api
+	    prefix: api_
+	# Help text rules, the period is "always", "never" or as most help texts.
+	help:
+	  minLength: 10
+	  period: always
+	# Unit policy of the metric names.
+	units:
+	  allowed: [seconds, bytes, ratio, celsius]
+	  forbidden: [octets, percent]
+	  replacements:
+	    octets: bytes
+	    percent: ratio
+	# Label name rules.
+	labels:
+	  max: 10
+	  deny: [user_id, email, ip, url]
+	  required:
+	    - path: "pkg/api/..."
+	      labels: [tenant]
+	# Severities of the rules, error, warning or info.
+	severities:
+	  Help: warning
+	  Conflict: error
 `
 
 var (
@@ -50,10 +181,20 @@ var (
 		2: "SUMMARY",
 		3: "UNTYPED",
 		4: "HISTOGRAM",
+		5: "GAUGE_HISTOGRAM",
+		6: "INFO",
+		7: "STATESET",
 	}
 	withVendor *bool
 )
 
+// The exit codes.
+const (
+	exitClean   = 0
+	exitIssues  = 1
+	exitFailure = 2
+)
+
 func init() {
 	// To see the log position, added for debugging.
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
@@ -63,6 +204,12 @@ func main() {
 	app := kingpin.New(filepath.Base(os.Args[0]), help)
 	app.Version("v0.3.0")
 	app.HelpFlag.Short('h')
+	app.Terminate(func(status int) {
+		if status != exitClean {
+			status = exitFailure
+		}
+		os.Exit(status)
+	})
 
 	listCmd := app.Command("list", "List metrics name.")
 	listPaths := listCmd.Arg("files", "Files to parse metrics.").Strings()
@@ -73,7 +220,13 @@ func main() {
 	listPrintAddModule := listCmd.Flag("add-module", "Add metric module column when printing the result.").Default("false").Bool()
 
 	listPrintAddHelp := listCmd.Flag("add-help", "Add metric help column when printing the result.").Default("false").Bool()
+	listPrintAddUpdates := listCmd.Flag("add-updates", "Add column with the number of sites updating the metric when printing the result.").
+		Default("false").Bool()
+	listPrintAddCleanup := listCmd.Flag("add-cleanup", "Add column telling whether the series of a vector are ever deleted when printing the result.").
+		Default("false").Bool()
 	listPrintFormat := listCmd.Flag("output", "Print result formatted as JSON/YAML/Markdown").Short('o').Enum("yaml", "json", "md")
+	listRev := listCmd.Flag("rev", "List the metrics of the files at the git revision, without checking it out.").
+		PlaceHolder("REV").String()
 
 	withVendor = listCmd.Flag("with-vendor", "Scan vendor packages.").Default("false").Bool()
 
@@ -83,45 +236,194 @@ func main() {
 		Default("false").Short('s').Bool()
 	disableLintFuncs := lintCmd.Flag("disable", "Disable lint functions (repeated)."+
 		"Supported options: Help, Counter, MetricUnits, HistogramSummaryReserved, MetricTypeInName, "+
-		"ReservedChars, CamelCase, UnitAbbreviations").Short('d').Enums(promlinter.LintFuncNames...)
-
-	parsedCmd := kingpin.MustParse(app.Parse(os.Args[1:]))
+		"ReservedChars, CamelCase, UnitAbbreviations, Exemplar, WillPanic, OpenMetrics, Escaping, Parsing, Suppression").Short('d').Enums(ruleIDs(promlinter.LintFuncNames)...)
+	enableLintFuncs := lintCmd.Flag("enable", "Enable optional lint functions (repeated). "+
+		"Supported options: GlobalRegisterer, StaleSeries, Unregistered, Unused, Conflict, NearDuplicate, "+
+		"LabelVocabulary, Namespace, HelpCapitalized, HelpPeriod, HelpMinLength, HelpRepeatsName, HelpPlaceholder, "+
+		"HelpEmpty, HelpDuplicate, HelpUnit, UnitPolicy, BestPractices, LabelReserved, LabelCharset, "+
+		"LabelTypeCollision, LabelCount, LabelDeny, LabelRequired, EscapedNames").Short('e').Enums(ruleIDs(promlinter.OptionalLintFuncNames)...)
+	lintConfig := lintCmd.Flag("config", "Read lint settings from a YAML file.").Short('c').String()
+	lintOpenMetrics := lintCmd.Flag("openmetrics", "Check the OpenMetrics conventions too, "+
+		"for metrics exposed with the OpenMetrics format.").Default("false").Bool()
+	lintNameValidation := lintCmd.Flag("name-validation", "Validation scheme of the metric and label names, "+
+		"legacy or utf8 as Prometheus 3.").Enum(promlinter.NameValidationLegacy, promlinter.NameValidationUTF8)
+	lintSeverities := lintCmd.Flag("severity", "Set the severity of a rule, error, warning or info (repeated), e.g. Help=warning.").
+		PlaceHolder("RULE=SEVERITY").StringMap()
+	lintFailOnWarning := lintCmd.Flag("fail-on-warning", "Exit with code 1 on warnings too, not only on errors.").
+		Default("false").Bool()
+	lintWriteBaseline := lintCmd.Flag("write-baseline", "Write the issues to a baseline file, instead of printing them.").
+		PlaceHolder("FILE").String()
+	lintBaseline := lintCmd.Flag("baseline", "Only report the issues which are not in the baseline file, "+
+		"and the baseline issues which are fixed.").PlaceHolder("FILE").String()
+	lintNewFromRev := lintCmd.Flag("new-from-rev", "Only report the issues on metrics whose declarations changed "+
+		"since the git revision, and on changed lines.").PlaceHolder("REV").String()
+
+	diffCmd := app.Command("diff", "Compare two metric inventories written by list -o json, printing the changes as markdown.")
+	diffOld := diffCmd.Arg("old", "Inventory of the metrics before the changes.").Required().String()
+	diffNew := diffCmd.Arg("new", "Inventory of the metrics after the changes.").Required().String()
+
+	parsedCmd, err := app.Parse(os.Args[1:])
+	if err != nil {
+		app.Fatalf("%s, try --help", err)
+	}
 	fileSet := token.NewFileSet()
 
-	res := 0
+	res := exitClean
 	switch parsedCmd {
 	case listCmd.FullCommand():
-		metrics := promlinter.RunList(fileSet, findFiles(*listPaths, fileSet), *listStrict)
+		var files []*ast.File
+		if *listRev != "" {
+			rev := &gitRevision{rev: *listRev}
+			files = findFiles(rev, *listPaths, fileSet)
+			rev.close()
+		} else {
+			files = findFiles(workTree{}, *listPaths, fileSet)
+		}
+		metrics := promlinter.RunList(fileSet, files, *listStrict)
 		p := printer{
 			fmt:         *listPrintFormat,
 			addHelp:     *listPrintAddHelp,
 			addPosition: *listPrintAddPos,
 			addModule:   *listPrintAddModule,
+			addUpdates:  *listPrintAddUpdates,
+			addCleanup:  *listPrintAddCleanup,
 			metrics:     metrics,
 		}
 
 		p.printMetrics()
 	case lintCmd.FullCommand():
-		setting := promlinter.Setting{Strict: *lintStrict, DisabledLintFuncs: *disableLintFuncs}
-		for _, iss := range promlinter.RunLint(fileSet, findFiles(*lintPaths, fileSet), setting) {
-			res++
-			fmt.Printf("%s %s %s\n", iss.Pos, iss.Metric, iss.Text)
+		setting := promlinter.Setting{}
+		if *lintConfig != "" {
+			var err error
+			if setting, err = promlinter.LoadSetting(*lintConfig); err != nil {
+				fatalf("Failed to load config %s: %v", *lintConfig, err)
+			}
+		}
+		setting.Strict = setting.Strict || *lintStrict
+		setting.OpenMetrics = setting.OpenMetrics || *lintOpenMetrics
+		if *lintNameValidation != "" {
+			setting.NameValidation = *lintNameValidation
+		}
+		setting.DisabledLintFuncs = append(setting.DisabledLintFuncs, *disableLintFuncs...)
+		setting.EnabledLintFuncs = append(setting.EnabledLintFuncs, *enableLintFuncs...)
+		for name, sev := range *lintSeverities {
+			if setting.Severities == nil {
+				setting.Severities = map[string]promlinter.Severity{}
+			}
+			setting.Severities[name] = promlinter.Severity(sev)
+		}
+		if err := setting.Validate(); err != nil {
+			fatalf("Invalid settings: %v", err)
+		}
+
+		if *lintNewFromRev != "" {
+			var err error
+			if setting.Changed, err = changedLines(*lintNewFromRev); err != nil {
+				fatalf("Failed to read the changes since %s: %v", *lintNewFromRev, err)
+			}
+		}
+		if *lintWriteBaseline != "" && *lintBaseline != "" {
+			fatalf("Flags --write-baseline and --baseline can't be used together")
+		}
+
+		issues := promlinter.RunLint(fileSet, findFiles(workTree{}, *lintPaths, fileSet), setting)
+		if *lintWriteBaseline != "" {
+			if err := promlinter.NewBaseline(*lintWriteBaseline, issues).Write(); err != nil {
+				fatalf("Failed to write baseline %s: %v", *lintWriteBaseline, err)
+			}
+			break
+		}
+
+		var fixed []promlinter.BaselineEntry
+		if *lintBaseline != "" {
+			baseline, err := promlinter.LoadBaseline(*lintBaseline)
+			if err != nil {
+				fatalf("Failed to load baseline %s: %v", *lintBaseline, err)
+			}
+			issues, fixed = baseline.Filter(issues)
+		}
+
+		for _, iss := range issues {
+			fmt.Printf("%s %s %s %s (%s)\n", iss.Pos, iss.Severity, iss.Metric, iss.Text, iss.RuleID)
+		}
+		for _, e := range fixed {
+			fmt.Printf("%s %s %d issue(s) of the baseline fixed, update the baseline (%s)\n", e.File, e.Metric, e.Count, e.Rule)
+		}
+		res = exitCode(issues, *lintFailOnWarning)
+	case diffCmd.FullCommand():
+		oldMetrics, err := loadInventory(*diffOld)
+		if err != nil {
+			fatalf("Failed to load inventory %s: %v", *diffOld, err)
+		}
+		newMetrics, err := loadInventory(*diffNew)
+		if err != nil {
+			fatalf("Failed to load inventory %s: %v", *diffNew, err)
+		}
+
+		changes := diffInventories(oldMetrics, newMetrics)
+		printChanges(os.Stdout, changes)
+		if breaking(changes) {
+			res = exitIssues
 		}
 	}
 
 	os.Exit(res)
 }
 
-func findFiles(paths []string, fileSet *token.FileSet) []*ast.File {
+// exitCode returns exitIssues if there are errors, or warnings when failing
+// on warnings, and exitClean otherwise.
+func exitCode(issues []promlinter.Issue, failOnWarning bool) int {
+	for _, iss := range issues {
+		if iss.Severity == promlinter.SeverityError ||
+			iss.Severity == promlinter.SeverityWarning && failOnWarning {
+			return exitIssues
+		}
+	}
+	return exitClean
+}
+
+// fatalf logs the failure of the tool and exits with exitFailure.
+func fatalf(format string, args ...interface{}) {
+	_ = log.Output(2, fmt.Sprintf(format, args...))
+	os.Exit(exitFailure)
+}
+
+// ruleIDs returns the rule IDs, and the aliases of these rules.
+func ruleIDs(ids []string) []string {
+	names := append([]string(nil), ids...)
+	for alias, id := range promlinter.RuleAliases {
+		for _, name := range ids {
+			if name == id {
+				names = append(names, alias)
+			}
+		}
+	}
+	return names
+}
+
+func findFiles(src fileSource, paths []string, fileSet *token.FileSet) []*ast.File {
 	var files []*ast.File
 	for _, path := range paths {
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			os.Exit(1)
+		// The files are always walked recursively, like with "./...".
+		if path == "..." || strings.HasSuffix(path, "/...") {
+			path = strings.TrimSuffix(strings.TrimSuffix(path, "..."), "/")
+			if path == "" {
+				path = "."
+			}
+		}
+
+		names, err := src.goFiles(path)
+		if err != nil {
+			fatalf("Failed to find %s: %v", path, err)
 		}
-		for f := range walkDir(path) {
-			file, err := parser.ParseFile(fileSet, f, nil, parser.AllErrors)
+		for _, f := range names {
+			b, err := src.readFile(f)
+			if err != nil {
+				fatalf("Failed to read %s: %v", f, err)
+			}
+			file, err := parser.ParseFile(fileSet, f, b, parser.AllErrors|parser.ParseComments)
 			if err != nil {
-				os.Exit(1)
+				fatalf("Failed to parse %s: %v", f, err)
 			}
 			files = append(files, file)
 		}
@@ -129,26 +431,32 @@ func findFiles(paths []string, fileSet *token.FileSet) []*ast.File {
 	return files
 }
 
+// isGoSource reports whether the file is a Go source file to parse, not a
+// test file nor a vendored one unless scanning vendor packages.
+func isGoSource(path string) bool {
+	sep := string(filepath.Separator)
+	if withVendor != nil && !*withVendor &&
+		(strings.HasPrefix(path, "vendor"+sep) || strings.Contains(path, sep+"vendor"+sep)) {
+		return false
+	}
+
+	name := filepath.Base(path)
+	return !strings.HasSuffix(name, "_test.go") && strings.HasSuffix(name, ".go")
+}
+
 func walkDir(root string) chan string {
 	out := make(chan string)
 
 	go func() {
 		defer close(out)
 		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-			sep := string(filepath.Separator)
-			if withVendor != nil && !*withVendor &&
-				(strings.HasPrefix(path, "vendor"+sep) || strings.Contains(path, sep+"vendor"+sep)) {
-				return nil
-			}
-
-			if !info.IsDir() && !strings.HasSuffix(info.Name(), "_test.go") &&
-				strings.HasSuffix(info.Name(), ".go") {
+			if !info.IsDir() && isGoSource(path) {
 				out <- path
 			}
 			return nil
 		})
 		if err != nil {
-			os.Exit(1)
+			fatalf("Failed to walk %s: %v", root, err)
 		}
 	}()
 
@@ -164,23 +472,20 @@ func (p *printer) printDefault() {
 		fieldSep = "|"
 	}
 
-	var (
-		fields []string
-	)
-
+	var fields []string
 	if p.addPosition || p.addModule {
-		fields = []string{
-			"POSITION", "TYPE", "NAME", "LABELS",
-		}
-	} else {
-		fields = []string{
-			"TYPE", "NAME", "LABELS",
-		}
+		fields = append(fields, "POSITION")
 	}
-
+	fields = append(fields, "TYPE", "NAME", "LABELS")
 	if p.addHelp {
 		fields = append(fields, "HELP")
 	}
+	if p.addUpdates {
+		fields = append(fields, "UPDATES")
+	}
+	if p.addCleanup {
+		fields = append(fields, "CLEANUP")
+	}
 
 	if p.fmt == "md" {
 		fmt.Fprintf(tw, "|%s|\n", strings.Join(fields, fieldSep))
@@ -204,43 +509,25 @@ func (p *printer) printDefault() {
 			labels = "N/A"
 		}
 
-		var lineArr []string
-
 		mname := *m.MetricFamily.Name
 		if p.fmt == "md" {
 			mname = fmt.Sprintf("`%s`", *m.MetricFamily.Name)
 			labels = fmt.Sprintf("`%s`", labels)
 		}
 
-		if (p.addPosition || p.addModule) && p.addHelp {
-			lineArr = []string{
-				p.pos(m.Pos.String()),
-				MetricType[int32(*m.MetricFamily.Type)],
-				mname,
-				labels,
-				help,
-			}
-		} else if p.addPosition || p.addModule {
-			lineArr = []string{
-				p.pos(m.Pos.String()),
-				MetricType[int32(*m.MetricFamily.Type)],
-				mname,
-				labels,
-			}
-
-		} else if p.addHelp {
-			lineArr = []string{
-				MetricType[int32(*m.MetricFamily.Type)],
-				mname,
-				labels,
-				help,
-			}
-		} else {
-			lineArr = []string{
-				MetricType[int32(*m.MetricFamily.Type)],
-				mname,
-				labels,
-			}
+		var lineArr []string
+		if p.addPosition || p.addModule {
+			lineArr = append(lineArr, p.pos(m.Positions))
+		}
+		lineArr = append(lineArr, MetricType[int32(*m.MetricFamily.Type)], mname, labels)
+		if p.addHelp {
+			lineArr = append(lineArr, help)
+		}
+		if p.addUpdates {
+			lineArr = append(lineArr, strconv.Itoa(len(m.Updates)))
+		}
+		if p.addCleanup {
+			lineArr = append(lineArr, cleanup(m))
 		}
 
 		if p.fmt == "md" {
@@ -254,20 +541,39 @@ func (p *printer) printDefault() {
 type printer struct {
 	fmt                             string
 	addHelp, addPosition, addModule bool
+	addUpdates, addCleanup          bool
 	metrics                         []promlinter.MetricFamilyWithPos
 }
 
-func (p *printer) pos(pos string) (x string) {
-	if p.addModule {
-		x = filepath.Dir(pos)
-	} else {
-		x = pos
+// cleanup tells whether the series of a vector are ever deleted.
+func cleanup(m promlinter.MetricFamilyWithPos) string {
+	if !m.IsVector() {
+		return "N/A"
+	}
+	if len(m.Deletes) > 0 {
+		return "yes"
+	}
+	return "no"
+}
+
+func (p *printer) pos(positions []token.Position) string {
+	var arr []string
+	for _, pos := range positions {
+		x := pos.String()
+		if p.addModule {
+			x = filepath.Dir(x)
+			if len(arr) > 0 && arr[len(arr)-1] == x {
+				continue
+			}
+		}
+		arr = append(arr, x)
 	}
 
+	x := strings.Join(arr, ",")
 	if p.fmt == "md" {
 		return fmt.Sprintf("*%s*", x) // italic file path
 	}
-	return
+	return x
 }
 
 func (p *printer) printMetrics() {
@@ -288,7 +594,7 @@ func (p *printer) printAsYaml() {
 	b, err := yaml.Marshal(toPrint(p.metrics))
 	if err != nil {
 		fmt.Printf("Failed: %v", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 	fmt.Print(string(b))
 
@@ -298,19 +604,25 @@ func (p *printer) printAsJson() {
 	b, err := json.MarshalIndent(toPrint(p.metrics), "", "  ")
 	if err != nil {
 		fmt.Printf("Failed: %v", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 	fmt.Print(string(b))
 }
 
 type MetricForPrinting struct {
-	Name     string
-	Help     string
-	Type     string
-	Filename string
-	Labels   []string
-	Line     int
-	Column   int
+	Name      string
+	Namespace string `json:",omitempty" yaml:",omitempty"`
+	Subsystem string `json:",omitempty" yaml:",omitempty"`
+	Help      string
+	Type      string
+	Filename  string
+	Labels    []string
+	Line      int
+	Column    int
+	// Positions are all the positions the metric is declared at.
+	Positions []string `json:",omitempty" yaml:",omitempty"`
+	Updates   []string `json:",omitempty" yaml:",omitempty"`
+	Deletes   []string `json:",omitempty" yaml:",omitempty"`
 }
 
 func toPrint(metrics []promlinter.MetricFamilyWithPos) []MetricForPrinting {
@@ -340,16 +652,27 @@ func toPrint(metrics []promlinter.MetricFamilyWithPos) []MetricForPrinting {
 			}
 
 			i := MetricForPrinting{
-				Name:     n,
-				Help:     h,
-				Type:     MetricType[int32(*m.MetricFamily.Type)],
-				Filename: m.Pos.Filename,
-				Line:     m.Pos.Line,
-				Column:   m.Pos.Column,
-				Labels:   labels,
+				Name:      n,
+				Help:      h,
+				Type:      MetricType[int32(*m.MetricFamily.Type)],
+				Filename:  m.Pos.Filename,
+				Line:      m.Pos.Line,
+				Column:    m.Pos.Column,
+				Labels:    labels,
+				Positions: positions(m.Positions),
+				Updates:   positions(m.Updates),
+				Deletes:   positions(m.Deletes),
 			}
 			p = append(p, i)
 		}
 	}
 	return p
 }
+
+func positions(pos []token.Position) []string {
+	var p []string
+	for _, x := range pos {
+		p = append(p, x.String())
+	}
+	return p
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// fileSource lists and reads the Go files to parse.
+type fileSource interface {
+	// goFiles returns the Go source files of the path, a file or a directory
+	// walked recursively.
+	goFiles(path string) ([]string, error)
+	readFile(name string) ([]byte, error)
+}
+
+// workTree reads the files of the file system.
+type workTree struct{}
+
+func (workTree) goFiles(path string) ([]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for f := range walkDir(path) {
+		names = append(names, f)
+	}
+	return names, nil
+}
+
+func (workTree) readFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// gitRevision reads the files stored at a revision of the git repository of
+// the current directory, without changing the working tree. The paths are
+// relative to the current directory, as in the working tree.
+type gitRevision struct {
+	rev string
+
+	// cat streams the files read from a single git cat-file process,
+	// started by the first read.
+	cat *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+func (g *gitRevision) goFiles(path string) ([]string, error) {
+	out, err := git("-c", "core.quotePath=false", "ls-tree", "-r", "-z", "--name-only", g.rev, "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	found := false
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f == "" {
+			continue
+		}
+		found = true
+		if f = filepath.FromSlash(f); isGoSource(f) {
+			names = append(names, f)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no such file or directory at revision %s", g.rev)
+	}
+	return names, nil
+}
+
+func (g *gitRevision) readFile(name string) ([]byte, error) {
+	name = filepath.ToSlash(name)
+	if !strings.HasPrefix(name, "../") {
+		name = "./" + name
+	}
+	if strings.Contains(name, "\n") {
+		return nil, fmt.Errorf("%s: can't read a file name with a newline at revision %s", name, g.rev)
+	}
+	if g.cat == nil {
+		if err := g.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Each object is answered by "<object> <type> <size>" and its content
+	// followed by a newline, or by "<name> missing".
+	if _, err := fmt.Fprintf(g.in, "%s:%s\n", g.rev, name); err != nil {
+		return nil, err
+	}
+	header, err := g.out.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %v", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("%s: no such file at revision %s", name, g.rev)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: bad header %q", header)
+	}
+	b := make([]byte, size+1)
+	if _, err := io.ReadFull(g.out, b); err != nil {
+		return nil, fmt.Errorf("git cat-file: %v", err)
+	}
+	if fields[1] != "blob" {
+		return nil, fmt.Errorf("%s: not a file at revision %s", name, g.rev)
+	}
+	return b[:size], nil
+}
+
+func (g *gitRevision) start() error {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git cat-file: %v", err)
+	}
+	g.cat, g.in, g.out = cmd, in, bufio.NewReader(out)
+	return nil
+}
+
+// close stops the git cat-file process, if started.
+func (g *gitRevision) close() error {
+	if g.cat == nil {
+		return nil
+	}
+	g.in.Close()
+	err := g.cat.Wait()
+	g.cat = nil
+	return err
+}
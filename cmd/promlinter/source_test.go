@@ -0,0 +1,44 @@
+package main
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yeya24/promlinter"
+)
+
+func TestGitRevision(t *testing.T) {
+	src := &gitRevision{rev: "HEAD"}
+	defer src.close()
+	names, err := src.goFiles("../../testdata")
+	if err != nil {
+		t.Skipf("not in a git repository: %v", err)
+	}
+	assert.Contains(t, names, "../../testdata/testdata.go")
+
+	wantNames, err := workTree{}.goFiles("../../testdata")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, wantNames, names)
+
+	b, err := src.readFile("../../testdata/testdata.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "package testdata")
+
+	// The process reading the files goes on after a missing file.
+	_, err = src.readFile("../../testdata/missing.go")
+	assert.Error(t, err)
+	_, err = src.readFile("../../testdata")
+	assert.Error(t, err)
+	b2, err := src.readFile("../../testdata/testdata.go")
+	require.NoError(t, err)
+	assert.Equal(t, b, b2)
+
+	_, err = src.goFiles("missing")
+	assert.Error(t, err)
+
+	fs := token.NewFileSet()
+	metrics := promlinter.RunList(fs, findFiles(src, []string{"../../testdata/..."}, fs), true)
+	assert.Equal(t, 11, len(metrics))
+}
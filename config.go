@@ -0,0 +1,185 @@
+package promlinter
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LibrarySetting classifies packages as libraries or binaries.
+type LibrarySetting struct {
+	// Paths are patterns of the package directories which are libraries.
+	// If empty, every package not named main is a library.
+	Paths []string `yaml:"paths"`
+	// Exclude are patterns of the package directories which are never libraries.
+	Exclude []string `yaml:"exclude"`
+}
+
+// NamespacePolicy requires the metrics of the packages it applies to to
+// share a namespace.
+type NamespacePolicy struct {
+	// Path is a pattern of the package directories, and Package a package
+	// name the policy applies to. If both are empty, it applies to all.
+	Path    string `yaml:"path"`
+	Package string `yaml:"package"`
+
+	// Namespace is the namespace of all the metrics, and Prefix the prefix
+	// of all the metric names. If both are empty, the metrics must share a
+	// namespace, per directory for a policy applying to all.
+	Namespace string `yaml:"namespace"`
+	Prefix    string `yaml:"prefix"`
+}
+
+// HelpSetting configures the help text rules.
+type HelpSetting struct {
+	// MinLength is the minimum number of characters of the help texts,
+	// 10 if zero.
+	MinLength int `yaml:"minLength"`
+	// Period is "always" if the help texts must end with a period, or
+	// "never". If empty, they must end as most help texts do.
+	Period string `yaml:"period"`
+}
+
+// UnitSetting is the unit policy of the metric names.
+type UnitSetting struct {
+	// Allowed are the units metric names may end with. If empty, all the
+	// units are allowed.
+	Allowed []string `yaml:"allowed"`
+	// Forbidden are the units metric names must not end with.
+	Forbidden []string `yaml:"forbidden"`
+	// Replacements map units to the preferred ones, by default octets to
+	// bytes and percent to ratio.
+	Replacements map[string]string `yaml:"replacements"`
+}
+
+// LabelSetting configures the label name rules.
+type LabelSetting struct {
+	// Max is the max number of labels of a metric, 10 if zero.
+	Max int `yaml:"max"`
+	// Deny are the patterns of the denied label names, by default user_id,
+	// email, ip and url.
+	Deny []string `yaml:"deny"`
+	// Required are the labels required by the metrics of some packages.
+	Required []LabelRequirement `yaml:"required"`
+}
+
+// LabelRequirement requires labels on the metrics of the packages it
+// applies to.
+type LabelRequirement struct {
+	// Path is a pattern of the package directories, and Package a package
+	// name the requirement applies to. If both are empty, it applies to all.
+	Path    string   `yaml:"path"`
+	Package string   `yaml:"package"`
+	Labels  []string `yaml:"labels"`
+}
+
+func (r LabelRequirement) appliesTo(pkg, dir string) bool {
+	return (r.Path == "" || matchPath(r.Path, dir)) && (r.Package == "" || r.Package == pkg)
+}
+
+func (p NamespacePolicy) appliesTo(pkg, dir string) bool {
+	return (p.Path == "" || matchPath(p.Path, dir)) && (p.Package == "" || p.Package == pkg)
+}
+
+// LoadSetting reads the setting from a YAML configuration file.
+func LoadSetting(filename string) (Setting, error) {
+	var s Setting
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return s, err
+	}
+
+	if err := yaml.UnmarshalStrict(b, &s); err != nil {
+		return s, err
+	}
+	return s, s.Validate()
+}
+
+// Validate checks the values of the setting which can't be checked while
+// decoding it.
+func (s Setting) Validate() error {
+	switch s.NameValidation {
+	case "", NameValidationLegacy, NameValidationUTF8:
+	default:
+		return fmt.Errorf("unknown name validation %q, must be %q or %q",
+			s.NameValidation, NameValidationLegacy, NameValidationUTF8)
+	}
+
+	switch s.Help.Period {
+	case "", "always", "never":
+	default:
+		return fmt.Errorf("unknown help period %q, must be %q or %q", s.Help.Period, "always", "never")
+	}
+
+	for name, sev := range s.Severities {
+		if _, ok := RuleByID(name); !ok {
+			return fmt.Errorf("unknown rule %q in severities", name)
+		}
+		if _, err := ParseSeverity(string(sev)); err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (s Setting) isEnabled(name string) bool {
+	for _, enabledFunc := range s.EnabledLintFuncs {
+		if ruleID(enabledFunc) == name {
+			return !s.isDisabled(name)
+		}
+	}
+	return false
+}
+
+// isLibrary reports whether the package in the directory is a library.
+func (l LibrarySetting) isLibrary(pkg, dir string) bool {
+	for _, pattern := range l.Exclude {
+		if matchPath(pattern, dir) {
+			return false
+		}
+	}
+
+	if len(l.Paths) == 0 {
+		return pkg != "main"
+	}
+
+	for _, pattern := range l.Paths {
+		if matchPath(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether the directory, or any of its trailing path
+// elements, matches the pattern. A pattern ending with "/..." also matches
+// all the subdirectories, e.g. "pkg/..." matches "./pkg/store/cache".
+func matchPath(pattern, dir string) bool {
+	pattern = strings.TrimPrefix(path.Clean(filepath.ToSlash(pattern)), "./")
+	recursive := false
+	if strings.HasSuffix(pattern, "/...") || pattern == "..." {
+		recursive = true
+		pattern = strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+	}
+
+	elems := strings.Split(strings.TrimPrefix(path.Clean(filepath.ToSlash(dir)), "/"), "/")
+	for i := range elems {
+		for j := len(elems); j > i; j-- {
+			if j < len(elems) && !recursive {
+				break
+			}
+			if ok, _ := path.Match(pattern, strings.Join(elems[i:j], "/")); ok {
+				return true
+			}
+		}
+		if recursive && pattern == "" {
+			return true
+		}
+	}
+	return false
+}
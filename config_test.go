@@ -0,0 +1,77 @@
+package promlinter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSetting(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "promlinter.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+strict: true
+disable: [Help]
+enable: [GlobalRegisterer]
+libraries:
+  paths: ["pkg/..."]
+  exclude: ["pkg/cmd/..."]
+`), 0o644))
+
+	s, err := LoadSetting(filename)
+	require.NoError(t, err)
+	assert.Equal(t, Setting{
+		Strict:            true,
+		DisabledLintFuncs: []string{"Help"},
+		EnabledLintFuncs:  []string{"GlobalRegisterer"},
+		Libraries: LibrarySetting{
+			Paths:   []string{"pkg/..."},
+			Exclude: []string{"pkg/cmd/..."},
+		},
+	}, s)
+
+	require.NoError(t, os.WriteFile(filename, []byte("unknown: true\n"), 0o644))
+	_, err = LoadSetting(filename)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filename, []byte("nameValidation: ascii\n"), 0o644))
+	_, err = LoadSetting(filename)
+	assert.EqualError(t, err, `unknown name validation "ascii", must be "legacy" or "utf8"`)
+
+	require.NoError(t, os.WriteFile(filename, []byte("help: {period: alway}\n"), 0o644))
+	_, err = LoadSetting(filename)
+	assert.EqualError(t, err, `unknown help period "alway", must be "always" or "never"`)
+
+	require.NoError(t, os.WriteFile(filename, []byte("severities: {Help: warning, lintUnitAbbreviations: info}\n"), 0o644))
+	s, err = LoadSetting(filename)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Severity{"Help": SeverityWarning, "lintUnitAbbreviations": SeverityInfo}, s.Severities)
+
+	require.NoError(t, os.WriteFile(filename, []byte("severities: {Unknown: warning}\n"), 0o644))
+	_, err = LoadSetting(filename)
+	assert.EqualError(t, err, `unknown rule "Unknown" in severities`)
+
+	require.NoError(t, os.WriteFile(filename, []byte("severities: {Help: fatal}\n"), 0o644))
+	_, err = LoadSetting(filename)
+	assert.EqualError(t, err, `rule Help: unknown severity "fatal", must be "error", "warning" or "info"`)
+}
+
+func TestMatchPath(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, dir string
+		match        bool
+	}{
+		{pattern: "pkg/store", dir: "pkg/store", match: true},
+		{pattern: "pkg/store", dir: "/src/repo/pkg/store", match: true},
+		{pattern: "pkg/store", dir: "pkg/store/cache", match: false},
+		{pattern: "pkg/...", dir: "./pkg/store/cache", match: true},
+		{pattern: "./pkg/...", dir: "pkg", match: true},
+		{pattern: "pkg/*", dir: "pkg/store", match: true},
+		{pattern: "cmd/...", dir: "pkg/store", match: false},
+		{pattern: "./...", dir: "pkg/store", match: true},
+	} {
+		assert.Equal(t, tc.match, matchPath(tc.pattern, tc.dir), "pattern %q, dir %q", tc.pattern, tc.dir)
+	}
+}
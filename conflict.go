@@ -0,0 +1,104 @@
+package promlinter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// definition is what a metric family must agree on wherever it is declared.
+type definition struct {
+	typ    string
+	labels string
+	help   string
+}
+
+func definitionOf(m *MetricFamilyWithPos) definition {
+	d := definition{
+		typ:  "UNKNOWN",
+		help: m.MetricFamily.GetHelp(),
+	}
+	if m.MetricFamily.Type != nil {
+		d.typ = metricTypeName(*m.MetricFamily.Type)
+	}
+
+	var labels []string
+	if len(m.MetricFamily.Metric) > 0 {
+		for _, label := range m.MetricFamily.Metric[0].Label {
+			labels = append(labels, strings.Trim(label.GetName(), `"`))
+		}
+	}
+	sort.Strings(labels)
+	d.labels = "[" + strings.Join(labels, " ") + "]"
+
+	return d
+}
+
+// lintConflicts reports metric families declared several times with a
+// different type, label set or help text, which fails the registration
+// or makes queries confusing.
+func (v *visitor) lintConflicts() {
+	byName := map[string][]int{}
+	var names []string
+	for i := range v.metrics {
+		name := v.metrics[i].MetricFamily.GetName()
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], i)
+	}
+
+	for _, name := range names {
+		indexes := byName[name]
+		if len(indexes) < 2 {
+			continue
+		}
+
+		defs := make([]definition, len(indexes))
+		var differ []string
+		for i, idx := range indexes {
+			defs[i] = definitionOf(&v.metrics[idx])
+		}
+		for _, d := range defs[1:] {
+			if d.typ != defs[0].typ && !contains(differ, "type") {
+				differ = append(differ, "type")
+			}
+			if d.labels != defs[0].labels && !contains(differ, "labels") {
+				differ = append(differ, "labels")
+			}
+			if d.help != defs[0].help && !contains(differ, "help") {
+				differ = append(differ, "help")
+			}
+		}
+		if len(differ) == 0 {
+			continue
+		}
+
+		var decls []string
+		for i, idx := range indexes {
+			var positions []string
+			for _, pos := range v.metrics[idx].Positions {
+				positions = append(positions, pos.String())
+			}
+			decls = append(decls, fmt.Sprintf("%s %s %q at %s",
+				defs[i].typ, defs[i].labels, defs[i].help, strings.Join(positions, ", ")))
+		}
+
+		first := v.metrics[indexes[0]]
+		v.issues = append(v.issues, Issue{
+			Pos:    first.Pos,
+			Metric: name,
+			Text: fmt.Sprintf("metric is declared with conflicting %s: %s",
+				strings.Join(differ, ", "), strings.Join(decls, "; ")),
+		})
+	}
+}
+
+func contains(arr []string, s string) bool {
+	for _, x := range arr {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
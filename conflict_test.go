@@ -0,0 +1,46 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflicts(t *testing.T) {
+	fs := token.NewFileSet()
+	a := parseSource(t, fs, "a/metrics.go", `package a
+
+var requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "requests_total",
+	Help: "Requests.",
+}, []string{"code"})
+
+var up = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "up",
+	Help: "Up.",
+})
+`)
+	b := parseSource(t, fs, "b/metrics.go", `package b
+
+var requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "requests_total",
+	Help: "Handled requests.",
+}, []string{"code", "method"})
+
+var up = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "up",
+	Help: "Up.",
+})
+`)
+
+	issues := RunLint(fs, []*ast.File{a, b}, Setting{EnabledLintFuncs: []string{"Conflict"}})
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "requests_total", issues[0].Metric)
+		assert.Equal(t, "a/metrics.go:3:41", issues[0].Pos.String())
+		assert.Equal(t, `metric is declared with conflicting labels, help: `+
+			`COUNTER [code] "Requests." at a/metrics.go:3:41; `+
+			`COUNTER [code method] "Handled requests." at b/metrics.go:3:41`, issues[0].Text)
+	}
+}
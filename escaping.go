@@ -0,0 +1,193 @@
+package promlinter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The name validation schemes.
+const (
+	// NameValidationLegacy only allows [a-zA-Z_:][a-zA-Z0-9_:]* metric
+	// names and [a-zA-Z_][a-zA-Z0-9_]* label names.
+	NameValidationLegacy = "legacy"
+	// NameValidationUTF8 allows any UTF-8 metric and label names, as
+	// Prometheus 3 does.
+	NameValidationUTF8 = "utf8"
+)
+
+// validMetricName reports whether the metric name is valid under the name
+// validation scheme.
+func (v *visitor) validMetricName(name string) bool {
+	if v.utf8Names {
+		return name != "" && utf8.ValidString(name)
+	}
+	return metricNameRE.MatchString(name)
+}
+
+// validLabelName reports whether the label name is valid under the name
+// validation scheme. Names starting with "__" are always reserved.
+func (v *visitor) validLabelName(name string) bool {
+	if v.utf8Names {
+		return name != "" && utf8.ValidString(name) && !strings.HasPrefix(name, "__")
+	}
+	return checkLabelName(name)
+}
+
+// escapingSchemes are the schemes escaping UTF-8 names for the systems only
+// supporting legacy names, in the order of Prometheus.
+var escapingSchemes = []struct {
+	name   string
+	escape func(string) string
+}{
+	{"underscores", escapeUnderscores},
+	{"dots", escapeDots},
+	{"values", escapeValues},
+}
+
+func isValidLegacyRune(r rune, i int) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_' || r == ':' || (r >= '0' && r <= '9' && i > 0)
+}
+
+// escapeUnderscores replaces the invalid characters with underscores.
+func escapeUnderscores(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if isValidLegacyRune(r, i) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// escapeDots replaces dots with "_dot_", underscores with "__" and the
+// other invalid characters with "__".
+func escapeDots(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_':
+			b.WriteString("__")
+		case r == '.':
+			b.WriteString("_dot_")
+		case isValidLegacyRune(r, i):
+			b.WriteRune(r)
+		default:
+			b.WriteString("__")
+		}
+	}
+	return b.String()
+}
+
+// escapeValues prefixes the invalid names with "U__", and replaces
+// underscores with "__" and the invalid characters with their code point.
+func escapeValues(name string) string {
+	if metricNameRE.MatchString(name) {
+		return name
+	}
+
+	var b strings.Builder
+	b.WriteString("U__")
+	for i, r := range name {
+		switch {
+		case r == '_':
+			b.WriteString("__")
+		case isValidLegacyRune(r, i):
+			b.WriteRune(r)
+		case !utf8.ValidRune(r):
+			b.WriteString("_FFFD_")
+		default:
+			b.WriteString("_" + strconv.FormatInt(int64(r), 16) + "_")
+		}
+	}
+	return b.String()
+}
+
+// lintEscaping reports the distinct names which collide once escaped, and
+// are ambiguous for the systems only supporting legacy names. The metric
+// names are compared across metrics, the label names within each metric.
+func (v *visitor) lintEscaping() {
+	var names []string
+	firstOf := map[string]*MetricFamilyWithPos{}
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		name := m.MetricFamily.GetName()
+		if _, ok := firstOf[name]; !ok {
+			firstOf[name] = m
+			names = append(names, name)
+		}
+
+		for _, c := range escapingCollisionPairs(m.labelNames()) {
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: name,
+				Text: fmt.Sprintf("label name %q collides with %q when escaped with the %s scheme as %q",
+					c.name, c.other, c.scheme, c.escaped),
+			})
+		}
+	}
+
+	for _, c := range escapingCollisionPairs(names) {
+		m := firstOf[c.name]
+		v.issues = append(v.issues, Issue{
+			Pos:    m.Pos,
+			Metric: c.name,
+			Text: fmt.Sprintf("metric name collides with %q at %s when escaped with the %s scheme as %q",
+				c.other, firstOf[c.other].Pos, c.scheme, c.escaped),
+		})
+	}
+}
+
+type collision struct {
+	name, other     string
+	scheme, escaped string
+}
+
+// escapingCollisionPairs returns, for each scheme, the names escaped like
+// a name before them.
+func escapingCollisionPairs(names []string) []collision {
+	var collisions []collision
+	for _, scheme := range escapingSchemes {
+		first := map[string]string{}
+		for _, name := range names {
+			escaped := scheme.escape(name)
+			if other, ok := first[escaped]; ok {
+				collisions = append(collisions, collision{name: name, other: other, scheme: scheme.name, escaped: escaped})
+				continue
+			}
+			first[escaped] = name
+		}
+	}
+	return collisions
+}
+
+// lintEscapedNames reports the metric and label names which aren't valid
+// legacy names, and so are escaped differently by each scheme.
+func (v *visitor) lintEscapedNames() {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		report := func(object, name string) {
+			var forms []string
+			for _, scheme := range escapingSchemes {
+				forms = append(forms, fmt.Sprintf("%q with %s", scheme.escape(name), scheme.name))
+			}
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: m.MetricFamily.GetName(),
+				Text:   fmt.Sprintf("%s %q is escaped as %s", object, name, strings.Join(forms, ", ")),
+			})
+		}
+
+		if name := m.MetricFamily.GetName(); !metricNameRE.MatchString(name) {
+			report("metric name", name)
+		}
+		for _, name := range m.labelNames() {
+			if !checkLabelName(name) && !strings.HasPrefix(name, "__") {
+				report("label name", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		name                      string
+		underscores, dots, values string
+	}{
+		{"http_requests_total", "http_requests_total", "http__requests__total", "http_requests_total"},
+		{"http.requests.total", "http_requests_total", "http_dot_requests_dot_total", "U__http_2e_requests_2e_total"},
+		{"http-requests", "http_requests", "http__requests", "U__http_2d_requests"},
+		{"1xx", "_xx", "__xx", "U___31_xx"},
+	} {
+		assert.Equal(t, tc.underscores, escapeUnderscores(tc.name), tc.name)
+		assert.Equal(t, tc.dots, escapeDots(tc.name), tc.name)
+		assert.Equal(t, tc.values, escapeValues(tc.name), tc.name)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "escaping.go", `package foo
+
+var (
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "http.server.requests_total", Help: "Requests."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "http_server_requests_total", Help: "Requests."})
+	_ = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "queue.length", Help: "Queue length."}, []string{"queue_name", "queue-name"})
+)
+`)
+	files := []*ast.File{file}
+
+	lint := func(s Setting) []string {
+		return issueTexts(RunLint(fs, files, s))
+	}
+
+	// Dotted names make client_golang panic with the legacy validation.
+	assert.Equal(t, []string{
+		`http.server.requests_total: registration will panic: "http.server.requests_total" is not a valid metric name`,
+		`queue.length: registration will panic: "queue.length" is not a valid metric name`,
+		`queue.length: registration will panic: "queue-name" is not a valid label name`,
+	}, lint(Setting{}))
+
+	assert.Equal(t, []string{
+		`queue.length: label name "queue-name" collides with "queue_name" when escaped with the underscores scheme as "queue_name"`,
+		`queue.length: label name "queue-name" collides with "queue_name" when escaped with the dots scheme as "queue__name"`,
+		`http_server_requests_total: metric name collides with "http.server.requests_total" at escaping.go:4:28 ` +
+			`when escaped with the underscores scheme as "http_server_requests_total"`,
+	}, lint(Setting{NameValidation: NameValidationUTF8}))
+
+	assert.Equal(t, []string{
+		`http.server.requests_total: metric name "http.server.requests_total" is escaped as ` +
+			`"http_server_requests_total" with underscores, "http_dot_server_dot_requests__total" with dots, ` +
+			`"U__http_2e_server_2e_requests__total" with values`,
+		`queue.length: metric name "queue.length" is escaped as ` +
+			`"queue_length" with underscores, "queue_dot_length" with dots, "U__queue_2e_length" with values`,
+		`queue.length: label name "queue-name" is escaped as ` +
+			`"queue_name" with underscores, "queue__name" with dots, "U__queue_2d_name" with values`,
+	}, lint(Setting{
+		NameValidation:    NameValidationUTF8,
+		DisabledLintFuncs: []string{"Escaping"},
+		EnabledLintFuncs:  []string{"EscapedNames"},
+	}))
+}
@@ -0,0 +1,164 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// exemplarMaxRunes is the max total number of runes allowed in exemplar
+// labels by OpenMetrics.
+const exemplarMaxRunes = 128
+
+// labelNameRE matches the label names valid under the legacy name scheme,
+// regardless of the reserved prefix.
+var labelNameRE = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// exemplarSupport maps the exemplar methods and interfaces to the only
+// metric type implementing them.
+var exemplarSupport = map[string]dto.MetricType{
+	"ObserveWithExemplar": dto.MetricType_HISTOGRAM,
+	"ExemplarObserver":    dto.MetricType_HISTOGRAM,
+	"AddWithExemplar":     dto.MetricType_COUNTER,
+	"ExemplarAdder":       dto.MetricType_COUNTER,
+}
+
+// checkLabelName mirrors the label name validation of client_golang.
+func checkLabelName(l string) bool {
+	return labelNameRE.MatchString(l) && !strings.HasPrefix(l, "__")
+}
+
+// lintExemplars checks the exemplars attached to metrics, which would make
+// client_golang panic or silently fail at runtime.
+func (v *visitor) lintExemplars() {
+	for _, c := range v.calls {
+		if _, ok := exemplarSupport[c.method]; !ok {
+			continue
+		}
+
+		m, asserted := v.resolve(c)
+		metricName := ""
+		if m != nil {
+			metricName = *m.MetricFamily.Name
+		}
+
+		switch c.method {
+		case "ObserveWithExemplar", "AddWithExemplar":
+			if len(c.args) > 1 {
+				v.lintExemplarLabels(c, metricName)
+			}
+			// The type assertion on the way is checked on its own.
+			if m != nil && !asserted {
+				v.lintExemplarSupport(c, m)
+			}
+
+		case "ExemplarObserver", "ExemplarAdder":
+			if m != nil {
+				v.lintExemplarSupport(c, m)
+			}
+		}
+	}
+}
+
+func (v *visitor) lintExemplarSupport(c metricCall, m *MetricFamilyWithPos) {
+	want := exemplarSupport[c.method]
+	kind := "counters"
+	if want == dto.MetricType_HISTOGRAM {
+		kind = "histograms"
+	}
+
+	_, isConst := constMetricArgNum[m.constructor]
+	if m.MetricFamily.Type != nil && *m.MetricFamily.Type == want &&
+		!isConst && !strings.HasSuffix(m.constructor, "Func") {
+		return
+	}
+
+	v.issues = append(v.issues, Issue{
+		Pos:    c.pos,
+		Metric: *m.MetricFamily.Name,
+		Text: fmt.Sprintf("%s is only implemented by %s, the metric is created with %s",
+			c.method, kind, m.constructor),
+	})
+}
+
+func (v *visitor) lintExemplarLabels(c metricCall, metricName string) {
+	lit := exemplarLabelsExpr(c.args[1])
+	if lit == nil {
+		return
+	}
+
+	var (
+		runes int
+		exact = true
+	)
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+
+		name, ok := v.parseValue("exemplar label name", kv.Key)
+		if !ok {
+			exact = false
+			continue
+		}
+		if !v.validLabelName(name) {
+			v.issues = append(v.issues, Issue{
+				Pos:    c.pos,
+				Metric: metricName,
+				Text:   fmt.Sprintf("exemplar label name %q is invalid", name),
+			})
+		}
+		runes += utf8.RuneCountInString(name)
+
+		value, ok := v.parseValue("exemplar label value", kv.Value)
+		if !ok {
+			exact = false
+			continue
+		}
+		if !utf8.ValidString(value) {
+			v.issues = append(v.issues, Issue{
+				Pos:    c.pos,
+				Metric: metricName,
+				Text:   fmt.Sprintf("exemplar label value %q is not valid UTF-8", value),
+			})
+		}
+		runes += utf8.RuneCountInString(value)
+	}
+
+	if runes <= exemplarMaxRunes {
+		return
+	}
+
+	text := fmt.Sprintf("exemplar labels have %d runes, exceeding the limit of %d", runes, exemplarMaxRunes)
+	if !exact {
+		text = fmt.Sprintf("exemplar labels have at least %d runes, exceeding the limit of %d", runes, exemplarMaxRunes)
+	}
+	v.issues = append(v.issues, Issue{
+		Pos:    c.pos,
+		Metric: metricName,
+		Text:   text,
+	})
+}
+
+// exemplarLabelsExpr returns the literal of the exemplar labels, e.g.
+// `prometheus.Labels{"trace_id": id}`.
+func exemplarLabelsExpr(n ast.Expr) *ast.CompositeLit {
+	switch t := n.(type) {
+	case *ast.CompositeLit:
+		return t
+	case *ast.UnaryExpr:
+		return exemplarLabelsExpr(t.X)
+	case *ast.Ident:
+		if rhs := aliasOf(t); rhs != nil {
+			if lit, ok := rhs.(*ast.CompositeLit); ok {
+				return lit
+			}
+		}
+	}
+	return nil
+}
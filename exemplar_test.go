@@ -0,0 +1,58 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExemplar(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "exemplar.go", `package foo
+
+var (
+	latency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "request_duration_seconds",
+		Help: "Request latency.",
+	}, []string{"method"})
+
+	size = prometheus.NewSummary(prometheus.SummaryOpts{
+		Name: "response_size_bytes",
+		Help: "Response size.",
+	})
+
+	inflight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "inflight_requests",
+		Help: "In-flight requests.",
+	})
+)
+
+func observe(traceID string) {
+	latency.WithLabelValues("GET").(prometheus.ExemplarObserver).ObserveWithExemplar(1, prometheus.Labels{"trace_id": traceID})
+
+	size.(prometheus.ExemplarObserver).ObserveWithExemplar(1, nil)
+
+	obs := latency.WithLabelValues("POST")
+	obs.(prometheus.ExemplarObserver).ObserveWithExemplar(1, prometheus.Labels{
+		"trace-id": "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	})
+
+	inflight.(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"trace_id": traceID})
+}
+`)
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{})
+	texts := issueTexts(issues)
+
+	assert.ElementsMatch(t, []string{
+		"response_size_bytes: ExemplarObserver is only implemented by histograms, the metric is created with NewSummary",
+		`request_duration_seconds: exemplar label name "trace-id" is invalid`,
+		"request_duration_seconds: exemplar labels have 136 runes, exceeding the limit of 128",
+		"inflight_requests: ExemplarAdder is only implemented by counters, the metric is created with NewGauge",
+	}, texts)
+
+	issues = RunLint(fs, []*ast.File{file}, Setting{DisabledLintFuncs: []string{"Exemplar"}})
+	assert.Empty(t, issues)
+}
@@ -0,0 +1,175 @@
+package promlinter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultHelpMinLength is the minimum number of runes of the help text.
+const defaultHelpMinLength = 10
+
+// placeholderRE matches the help texts which were never written.
+var placeholderRE = regexp.MustCompile(`(?i)\b(todo|fixme|xxx|tbd|placeholder|lorem ipsum)\b`)
+
+// helpTexts returns the lint function of a help text rule. promlint only
+// reports missing help texts, empty ones are reported by HelpEmpty and
+// skipped by the other rules.
+func helpTexts(fn func(m *MetricFamilyWithPos, help string) []string) func(*visitor, Setting) {
+	return func(v *visitor, _ Setting) {
+		v.lintHelpTexts(fn)
+	}
+}
+
+func (v *visitor) lintHelpTexts(fn func(m *MetricFamilyWithPos, help string) []string) {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		if help := strings.TrimSpace(m.MetricFamily.GetHelp()); help != "" {
+			v.appendIssues(m, fn(m, help))
+		}
+	}
+}
+
+func (v *visitor) appendIssues(m *MetricFamilyWithPos, texts []string) {
+	for _, text := range texts {
+		v.issues = append(v.issues, Issue{
+			Pos:    m.Pos,
+			Metric: m.MetricFamily.GetName(),
+			Text:   text,
+		})
+	}
+}
+
+func lintHelpCapitalized(_ *MetricFamilyWithPos, help string) []string {
+	if r, _ := utf8.DecodeRuneInString(help); unicode.IsLower(r) {
+		return []string{"help text should start with a capital letter"}
+	}
+	return nil
+}
+
+// lintHelpPeriod reports the help texts not ending as the period setting
+// says, "always" with a period or "never". If empty, the help texts must
+// end as most do.
+func (v *visitor) lintHelpPeriod(period string) {
+	if period == "" {
+		period = v.helpPeriod()
+	}
+
+	v.lintHelpTexts(func(_ *MetricFamilyWithPos, help string) []string {
+		switch hasPeriod := strings.HasSuffix(help, "."); {
+		case period == "always" && !hasPeriod:
+			return []string{"help text should end with a period"}
+		case period == "never" && hasPeriod:
+			return []string{"help text should not end with a period"}
+		}
+		return nil
+	})
+}
+
+// helpPeriod returns the ending used by most help texts, "always" if they
+// end with a period or "never" otherwise.
+func (v *visitor) helpPeriod() string {
+	with, without := 0, 0
+	for _, m := range v.metrics {
+		help := strings.TrimSpace(m.MetricFamily.GetHelp())
+		switch {
+		case help == "":
+		case strings.HasSuffix(help, "."):
+			with++
+		default:
+			without++
+		}
+	}
+
+	if with >= without {
+		return "always"
+	}
+	return "never"
+}
+
+func (v *visitor) lintHelpMinLength(minLength int) {
+	if minLength == 0 {
+		minLength = defaultHelpMinLength
+	}
+
+	v.lintHelpTexts(func(_ *MetricFamilyWithPos, help string) []string {
+		if n := utf8.RuneCountInString(help); n < minLength {
+			return []string{fmt.Sprintf("help text has %d characters, fewer than the minimum of %d", n, minLength)}
+		}
+		return nil
+	})
+}
+
+func lintHelpRepeatsName(m *MetricFamilyWithPos, help string) []string {
+	if repeatsName(help, m.MetricFamily.GetName()) {
+		return []string{"help text only repeats the metric name"}
+	}
+	return nil
+}
+
+// repeatsName reports whether all the words of the help text are tokens of
+// the metric name, e.g. "Requests total" for requests_total.
+func repeatsName(help, name string) bool {
+	tokens := map[string]bool{}
+	for _, token := range strings.Split(strings.ToLower(name), "_") {
+		tokens[token] = true
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(help), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if !tokens[word] {
+			return false
+		}
+	}
+	return len(words) > 0
+}
+
+func lintHelpPlaceholder(_ *MetricFamilyWithPos, help string) []string {
+	if match := placeholderRE.FindString(help); match != "" {
+		return []string{fmt.Sprintf("help text contains placeholder %q", match)}
+	}
+	return nil
+}
+
+// lintHelpEmpty reports the help texts which are set but empty, which
+// promlint doesn't report.
+func (v *visitor) lintHelpEmpty() {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		if m.MetricFamily.Help != nil && strings.TrimSpace(m.MetricFamily.GetHelp()) == "" {
+			v.appendIssues(m, []string{"help text is empty"})
+		}
+	}
+}
+
+// lintHelpDuplicates reports distinct metrics sharing the same help text,
+// which is usually copy-pasted from another metric.
+func (v *visitor) lintHelpDuplicates() {
+	first := map[string]*MetricFamilyWithPos{}
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		help := strings.TrimSpace(m.MetricFamily.GetHelp())
+		if help == "" {
+			continue
+		}
+
+		f, ok := first[help]
+		if !ok {
+			first[help] = m
+			continue
+		}
+		if f.MetricFamily.GetName() == m.MetricFamily.GetName() {
+			continue
+		}
+
+		v.issues = append(v.issues, Issue{
+			Pos:    m.Pos,
+			Metric: m.MetricFamily.GetName(),
+			Text:   fmt.Sprintf("help text is identical to the help of %q at %s", f.MetricFamily.GetName(), f.Pos),
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelpQuality(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "help.go", `package foo
+
+var (
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Total number of requests."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "errors_total", Help: "errors total"})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_length", Help: "TODO: describe this."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_capacity", Help: ""})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "workers", Help: "Total number of requests."})
+)
+`)
+	files := []*ast.File{file}
+
+	lint := func(s Setting) []string {
+		return issueTexts(RunLint(fs, files, s))
+	}
+
+	assert.Empty(t, lint(Setting{}))
+
+	assert.Equal(t, []string{
+		"errors_total: help text should start with a capital letter",
+		"errors_total: help text should end with a period",
+		"errors_total: help text only repeats the metric name",
+		`queue_length: help text contains placeholder "TODO"`,
+		"queue_capacity: help text is empty",
+		`workers: help text is identical to the help of "requests_total" at help.go:4:28`,
+	}, lint(Setting{EnabledLintFuncs: []string{
+		"HelpCapitalized", "HelpPeriod", "HelpRepeatsName", "HelpPlaceholder", "HelpEmpty", "HelpDuplicate",
+	}}))
+
+	assert.Equal(t, []string{
+		"requests_total: help text should not end with a period",
+		"queue_length: help text should not end with a period",
+		"workers: help text should not end with a period",
+		"errors_total: help text has 12 characters, fewer than the minimum of 20",
+	}, lint(Setting{
+		EnabledLintFuncs: []string{"HelpPeriod", "HelpMinLength"},
+		Help:             HelpSetting{MinLength: 20, Period: "never"},
+	}))
+}
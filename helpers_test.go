@@ -0,0 +1,26 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func parseSource(t *testing.T, fs *token.FileSet, filename, src string) *ast.File {
+	t.Helper()
+	file, err := parser.ParseFile(fs, filename, src, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// issueTexts returns the issues as "metric: text", in order.
+func issueTexts(issues []Issue) []string {
+	var texts []string
+	for _, iss := range issues {
+		texts = append(texts, iss.Metric+": "+iss.Text)
+	}
+	return texts
+}
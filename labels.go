@@ -0,0 +1,109 @@
+package promlinter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// defaultMaxLabels is the max number of labels of a metric, used if
+	// none is configured.
+	defaultMaxLabels = 10
+)
+
+// defaultDeniedLabels are the patterns of the denied label names, used if
+// none are configured. Their values are unbounded or sensitive.
+var defaultDeniedLabels = []string{"user_id", "email", "ip", "url"}
+
+// labelNames returns the lint function of a rule checking each label name
+// of the metrics on its own.
+func labelNames(fn func(m *MetricFamilyWithPos, name string) []string) func(*visitor, Setting) {
+	return func(v *visitor, _ Setting) {
+		v.lintLabels(fn)
+	}
+}
+
+func (v *visitor) lintLabels(fn func(m *MetricFamilyWithPos, name string) []string) {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		for _, name := range m.labelNames() {
+			v.appendIssues(m, fn(m, name))
+		}
+	}
+}
+
+func lintLabelReserved(_ *MetricFamilyWithPos, name string) []string {
+	if strings.HasPrefix(name, "__") {
+		return []string{fmt.Sprintf("label name %q starts with \"__\", which is reserved for internal use", name)}
+	}
+	return nil
+}
+
+func lintLabelCharset(_ *MetricFamilyWithPos, name string) []string {
+	if !labelNameRE.MatchString(name) {
+		return []string{fmt.Sprintf("label name %q is invalid under the legacy name scheme, "+
+			"which only allows [a-zA-Z_][a-zA-Z0-9_]*", name)}
+	}
+	return nil
+}
+
+func lintLabelTypeCollision(m *MetricFamilyWithPos, name string) []string {
+	typ := m.MetricFamily.GetType()
+	for t, reserved := range reservedLabels {
+		if name == reserved.label && typ != t {
+			return []string{fmt.Sprintf("label name %q is reserved for %s, the metric type is %s",
+				name, reserved.types, strings.ToLower(metricTypeName(typ)))}
+		}
+	}
+	return nil
+}
+
+func (v *visitor) lintLabelDeny(denied []string) {
+	if denied == nil {
+		denied = defaultDeniedLabels
+	}
+
+	v.lintLabels(func(_ *MetricFamilyWithPos, name string) []string {
+		if matchAny(denied, name) {
+			return []string{fmt.Sprintf("label name %q is denied, its values are unbounded or sensitive", name)}
+		}
+		return nil
+	})
+}
+
+func (v *visitor) lintLabelCount(maxLabels int) {
+	if maxLabels == 0 {
+		maxLabels = defaultMaxLabels
+	}
+
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		if n := len(m.labelNames()); n > maxLabels {
+			v.appendIssues(m, []string{fmt.Sprintf("metric has %d labels, more than the maximum of %d", n, maxLabels)})
+		}
+	}
+}
+
+// lintLabelRequired reports the metrics missing a label required by one of
+// the requirements applying to their package.
+func (v *visitor) lintLabelRequired(requirements []LabelRequirement) {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		names := m.labelNames()
+		dir := filepath.Dir(m.Pos.Filename)
+
+		var texts []string
+		for _, r := range requirements {
+			if !r.appliesTo(m.pkg, dir) {
+				continue
+			}
+			for _, label := range r.Labels {
+				if !contains(names, label) {
+					texts = append(texts, fmt.Sprintf("metric is missing the required label %q", label))
+				}
+			}
+		}
+		v.appendIssues(m, texts)
+	}
+}
@@ -0,0 +1,49 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelNames(t *testing.T) {
+	fs := token.NewFileSet()
+	api := parseSource(t, fs, "pkg/api/metrics.go", `package api
+
+var (
+	_ = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"tenant", "user_id"})
+	_ = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "queue_length", Help: "Queue length."}, []string{"__queue", "queue.name"})
+	_ = prometheus.NewSummaryVec(prometheus.SummaryOpts{Name: "request_duration_seconds", Help: "Latency."}, []string{"tenant", "le"})
+)
+`)
+	store := parseSource(t, fs, "pkg/store/metrics.go", `package store
+
+var _ = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "blocks", Help: "Blocks."}, []string{"a", "b", "c"})
+`)
+	files := []*ast.File{api, store}
+
+	lint := func(s Setting) []string {
+		return issueTexts(RunLint(fs, files, s))
+	}
+
+	s := Setting{
+		DisabledLintFuncs: []string{"WillPanic", "HistogramSummaryReserved"},
+		EnabledLintFuncs: []string{
+			"LabelReserved", "LabelCharset", "LabelTypeCollision", "LabelCount", "LabelDeny", "LabelRequired",
+		},
+		Labels: LabelSetting{
+			Max:      2,
+			Required: []LabelRequirement{{Path: "pkg/api/...", Labels: []string{"tenant"}}},
+		},
+	}
+	assert.Equal(t, []string{
+		`queue_length: label name "__queue" starts with "__", which is reserved for internal use`,
+		`queue_length: label name "queue.name" is invalid under the legacy name scheme, which only allows [a-zA-Z_][a-zA-Z0-9_]*`,
+		`request_duration_seconds: label name "le" is reserved for histograms, the metric type is summary`,
+		"blocks: metric has 3 labels, more than the maximum of 2",
+		`requests_total: label name "user_id" is denied, its values are unbounded or sensitive`,
+		`queue_length: metric is missing the required label "tenant"`,
+	}, lint(s))
+}
@@ -0,0 +1,107 @@
+package promlinter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// lintNamespaces reports the metrics breaking the namespace policy of their
+// package. Without any policy, the metrics of each directory must share a
+// namespace.
+func (v *visitor) lintNamespaces(policies []NamespacePolicy) {
+	if len(policies) == 0 {
+		policies = []NamespacePolicy{{}}
+	}
+
+	type group struct {
+		scope   string
+		metrics []*MetricFamilyWithPos
+	}
+	var groups []*group
+	byScope := map[string]*group{}
+
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		dir := filepath.Dir(m.Pos.Filename)
+
+		var policy *NamespacePolicy
+		for j := range policies {
+			if policies[j].appliesTo(m.pkg, dir) {
+				policy = &policies[j]
+				break
+			}
+		}
+		if policy == nil {
+			continue
+		}
+
+		report := func(format string, a ...interface{}) {
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: m.MetricFamily.GetName(),
+				Text:   fmt.Sprintf(format, a...),
+			})
+		}
+
+		switch {
+		case policy.Namespace != "":
+			if m.Namespace == "" && !strings.HasPrefix(m.MetricFamily.GetName(), policy.Namespace+"_") {
+				report("metric has no namespace, should be %q", policy.Namespace)
+			} else if m.Namespace != "" && m.Namespace != policy.Namespace {
+				report("metric namespace %q should be %q", m.Namespace, policy.Namespace)
+			}
+
+		case policy.Prefix != "":
+			if !strings.HasPrefix(m.MetricFamily.GetName(), policy.Prefix) {
+				report("metric name should start with %q", policy.Prefix)
+			}
+
+		default:
+			scope := policy.Path
+			if policy.Package != "" {
+				scope = "package " + policy.Package
+			}
+			if scope == "" {
+				scope = dir
+			}
+
+			g, ok := byScope[scope]
+			if !ok {
+				g = &group{scope: scope}
+				byScope[scope] = g
+				groups = append(groups, g)
+			}
+			g.metrics = append(g.metrics, m)
+		}
+	}
+
+	for _, g := range groups {
+		counts := map[string]int{}
+		for _, m := range g.metrics {
+			if m.Namespace != "" {
+				counts[m.Namespace]++
+			}
+		}
+
+		for _, m := range g.metrics {
+			if m.Namespace == "" {
+				v.issues = append(v.issues, Issue{
+					Pos:    m.Pos,
+					Metric: m.MetricFamily.GetName(),
+					Text:   "metric has no namespace",
+				})
+				continue
+			}
+
+			if preferred := preferredForm(counts); m.Namespace != preferred {
+				v.issues = append(v.issues, Issue{
+					Pos:    m.Pos,
+					Metric: m.MetricFamily.GetName(),
+					Text: fmt.Sprintf("metric namespace %q differs from %q used by the other metrics in %s",
+						m.Namespace, preferred, g.scope),
+				})
+			}
+		}
+	}
+}
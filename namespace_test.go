@@ -0,0 +1,56 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaces(t *testing.T) {
+	fs := token.NewFileSet()
+	store := parseSource(t, fs, "pkg/store/metrics.go", `package store
+
+var (
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Namespace: "store", Name: "reads_total", Help: "Reads."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Namespace: "store", Subsystem: "cache", Name: "hits_total", Help: "Hits."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Namespace: "storage", Name: "writes_total", Help: "Writes."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "errors_total", Help: "Errors."})
+)
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(prometheus.NewDesc(
+		prometheus.BuildFQName("store", "blocks", "loaded"), "Loaded blocks.", nil, nil,
+	), prometheus.GaugeValue, 1)
+}
+`)
+	files := []*ast.File{store}
+
+	metrics := RunList(fs, files, false)
+	var fields [][2]string
+	for _, m := range metrics {
+		fields = append(fields, [2]string{m.Namespace, m.Subsystem})
+	}
+	assert.ElementsMatch(t, [][2]string{{"store", ""}, {"store", "cache"}, {"storage", ""}, {"", ""}, {"store", "blocks"}}, fields)
+
+	issues := RunLint(fs, files, Setting{EnabledLintFuncs: []string{"Namespace"}})
+	texts := issueTexts(issues)
+	assert.Equal(t, []string{
+		`storage_writes_total: metric namespace "storage" differs from "store" used by the other metrics in pkg/store`,
+		"errors_total: metric has no namespace",
+	}, texts)
+
+	issues = RunLint(fs, files, Setting{
+		EnabledLintFuncs: []string{"Namespace"},
+		Namespaces: []NamespacePolicy{
+			{Path: "cmd/..."},
+			{Package: "store", Prefix: "store_"},
+		},
+	})
+	texts = issueTexts(issues)
+	assert.Equal(t, []string{
+		`storage_writes_total: metric name should start with "store_"`,
+		`errors_total: metric name should start with "store_"`,
+	}, texts)
+}
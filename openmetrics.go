@@ -0,0 +1,120 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// The OpenMetrics types missing in client_model. GaugeHistogram has the
+// value used by newer client_model versions.
+const (
+	MetricTypeGaugeHistogram dto.MetricType = 5
+	MetricTypeInfo           dto.MetricType = 6
+	MetricTypeStateSet       dto.MetricType = 7
+)
+
+var openMetricsTypeNames = map[dto.MetricType]string{
+	MetricTypeGaugeHistogram: "GAUGE_HISTOGRAM",
+	MetricTypeInfo:           "INFO",
+	MetricTypeStateSet:       "STATESET",
+}
+
+// metricTypeName returns the name of the metric type, including the
+// OpenMetrics types missing in client_model.
+func metricTypeName(t dto.MetricType) string {
+	if name, ok := openMetricsTypeNames[t]; ok {
+		return name
+	}
+	return t.String()
+}
+
+// openMetricsReservedSuffixes are the suffixes OpenMetrics appends to the
+// metric family name for its samples, besides the ones checked by promlint.
+var openMetricsReservedSuffixes = []string{"_created", "_bucket", "_gcount", "_gsum"}
+
+// openMetricsBaseUnits are the base units of the known units.
+var openMetricsBaseUnits = map[string]bool{
+	"seconds": true,
+	"bytes":   true,
+	"ratio":   true,
+	"celsius": true,
+	"kelvin":  true,
+}
+
+// lintOpenMetrics reports the metrics breaking the OpenMetrics naming
+// conventions, which matter when the metrics are exposed with the
+// OpenMetrics format. The counters without the _total suffix are left to
+// the Counter rule, if it runs.
+func (v *visitor) lintOpenMetrics(counterRan bool) {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		name, typ := m.MetricFamily.GetName(), m.MetricFamily.GetType()
+		u := nameUnit(name)
+		_, isUnit := unitWords[u]
+
+		var texts []string
+		if !counterRan && typ == dto.MetricType_COUNTER && !strings.HasSuffix(name, "_total") {
+			texts = append(texts, "OpenMetrics counters must have the _total suffix")
+		}
+
+		for _, suffix := range openMetricsReservedSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				texts = append(texts, fmt.Sprintf("metric name must not end with %q, which is reserved by OpenMetrics", suffix))
+			}
+		}
+
+		switch typ {
+		case MetricTypeInfo:
+			if !strings.HasSuffix(name, "_info") {
+				texts = append(texts, "OpenMetrics info metrics must have the _info suffix")
+			}
+			if isUnit {
+				texts = append(texts, "OpenMetrics info metrics must not have a unit")
+			}
+		case MetricTypeGaugeHistogram:
+			if strings.HasSuffix(name, "_total") {
+				texts = append(texts, "OpenMetrics gauge histograms must not have the _total suffix")
+			}
+		case MetricTypeStateSet:
+			if isUnit {
+				texts = append(texts, "OpenMetrics statesets must not have a unit")
+			}
+			texts = append(texts, lintStateSetLabels(name, m.labelNames())...)
+		default:
+			if isUnit && !openMetricsBaseUnits[u] {
+				texts = append(texts, fmt.Sprintf("unit %q is not an OpenMetrics base unit", u))
+			}
+		}
+
+		// The unit must be the suffix of the name, only followed by the
+		// suffixes of the samples, e.g. request_duration_seconds_total.
+		for _, token := range strings.Split(name, "_") {
+			if openMetricsBaseUnits[token] && token != u {
+				texts = append(texts, fmt.Sprintf("unit %q must be the suffix of the metric name", token))
+				break
+			}
+		}
+
+		v.appendIssues(m, texts)
+	}
+}
+
+// lintStateSetLabels reports the labels of a stateset breaking the
+// OpenMetrics conventions, the state being in a label named after the
+// metric. Nothing is reported if the labels are unknown.
+func lintStateSetLabels(name string, labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	var texts []string
+	if contains(labels, "le") {
+		texts = append(texts, `OpenMetrics statesets must not have a "le" label`)
+	}
+	if !contains(labels, name) {
+		texts = append(texts, fmt.Sprintf("OpenMetrics statesets must have a label named after the metric, %q", name))
+	}
+	return texts
+}
@@ -0,0 +1,94 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenMetrics(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "openmetrics.go", `package foo
+
+var (
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests", Help: "Requests."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "jobs_created", Help: "Jobs creation time."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_gcount", Help: "Queue count."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "uptime_hours", Help: "Uptime."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "request_seconds_duration_total", Help: "Latency."})
+
+	_ = []generator.FamilyGenerator{
+		*generator.NewFamilyGenerator("kube_pod_labels", "Pod labels.", metric.Info, "", nil),
+		*generator.NewFamilyGenerator("kube_pod_status_phase", "Pod phase.", metric.StateSet, "", nil),
+		*generator.NewFamilyGenerator("kube_pod_start_seconds", "Pod start.", metric.StateSet, "", nil),
+	}
+)
+`)
+	files := []*ast.File{file}
+
+	metrics := RunList(fs, files, false)
+	var types []string
+	for _, m := range metrics {
+		types = append(types, metricTypeName(m.MetricFamily.GetType()))
+	}
+	assert.ElementsMatch(t, []string{"COUNTER", "GAUGE", "GAUGE", "GAUGE", "COUNTER", "INFO", "STATESET", "STATESET"}, types)
+
+	lint := func(s Setting) []string {
+		return issueTexts(RunLint(fs, files, s))
+	}
+
+	s := Setting{DisabledLintFuncs: []string{"Counter", "MetricUnits"}}
+	assert.Empty(t, lint(s))
+
+	s.OpenMetrics = true
+	assert.Equal(t, []string{
+		"requests: OpenMetrics counters must have the _total suffix",
+		`jobs_created: metric name must not end with "_created", which is reserved by OpenMetrics`,
+		`queue_gcount: metric name must not end with "_gcount", which is reserved by OpenMetrics`,
+		`uptime_hours: unit "hours" is not an OpenMetrics base unit`,
+		`request_seconds_duration_total: unit "seconds" must be the suffix of the metric name`,
+		"kube_pod_labels: OpenMetrics info metrics must have the _info suffix",
+		"kube_pod_start_seconds: OpenMetrics statesets must not have a unit",
+	}, lint(s))
+
+	// The counter without the _total suffix is only reported once.
+	counter := lint(Setting{OpenMetrics: true, DisabledLintFuncs: []string{"MetricUnits"}})
+	assert.Contains(t, counter, `requests: counter metrics should have "_total" suffix`)
+	assert.NotContains(t, counter, "requests: OpenMetrics counters must have the _total suffix")
+
+	s.DisabledLintFuncs = append(s.DisabledLintFuncs, "OpenMetrics")
+	assert.Empty(t, lint(s))
+}
+
+func TestOpenMetricsKnownTypes(t *testing.T) {
+	family := func(name string, typ dto.MetricType, labels ...string) MetricFamilyWithPos {
+		metric := &dto.Metric{}
+		for i := range labels {
+			metric.Label = append(metric.Label, &dto.LabelPair{Name: &labels[i]})
+		}
+		return MetricFamilyWithPos{
+			MetricFamily: &dto.MetricFamily{Name: &name, Type: &typ, Metric: []*dto.Metric{metric}},
+			Positions:    []token.Position{{Filename: "openmetrics.go", Line: 1}},
+		}
+	}
+
+	v := newVisitor(token.NewFileSet(), false)
+	v.metrics = []MetricFamilyWithPos{
+		family("queue_wait_seconds_total", MetricTypeGaugeHistogram),
+		family("queue_wait_seconds", MetricTypeGaugeHistogram),
+		family("pod_phase", MetricTypeStateSet, "pod", "phase"),
+		family("node_condition", MetricTypeStateSet, "node", `"le"`, "node_condition"),
+		family("job_state", MetricTypeStateSet, "job", "job_state"),
+	}
+	v.lintOpenMetrics(false)
+
+	texts := issueTexts(v.issues)
+	assert.Equal(t, []string{
+		"queue_wait_seconds_total: OpenMetrics gauge histograms must not have the _total suffix",
+		`pod_phase: OpenMetrics statesets must have a label named after the metric, "pod_phase"`,
+		`node_condition: OpenMetrics statesets must not have a "le" label`,
+	}, texts)
+}
@@ -0,0 +1,170 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+var metricNameRE = regexp.MustCompile("^[a-zA-Z_:][a-zA-Z0-9_:]*$")
+
+// reservedLabels are the label names client_golang doesn't allow for a
+// metric type, as they are used for the buckets and quantiles.
+var reservedLabels = map[dto.MetricType]struct{ label, types string }{
+	dto.MetricType_HISTOGRAM: {label: "le", types: "histograms"},
+	dto.MetricType_SUMMARY:   {label: "quantile", types: "summaries"},
+}
+
+// lintWillPanic replays the validation client_golang runs when a metric is
+// created or registered, and reports the metrics which will make it panic.
+func (v *visitor) lintWillPanic() {
+	for _, m := range v.metrics {
+		report := func(format string, a ...interface{}) {
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: m.MetricFamily.GetName(),
+				Text:   fmt.Sprintf(format, a...),
+			})
+		}
+
+		if name := m.MetricFamily.GetName(); !v.validMetricName(name) {
+			report("registration will panic: %q is not a valid metric name", name)
+		}
+
+		seen := map[string]bool{}
+		if len(m.MetricFamily.Metric) > 0 {
+			for _, label := range m.MetricFamily.Metric[0].Label {
+				name := strings.Trim(label.GetName(), `"`)
+				if !v.validLabelName(name) {
+					report("registration will panic: %q is not a valid label name", name)
+				}
+
+				if seen[name] {
+					report("registration will panic: duplicate label name %q", name)
+				}
+				seen[name] = true
+			}
+		}
+
+		if m.MetricFamily.Type != nil {
+			if reserved, ok := reservedLabels[*m.MetricFamily.Type]; ok && seen[reserved.label] {
+				report("creation will panic: %q is not allowed as label name in %s", reserved.label, reserved.types)
+			}
+		}
+
+		if m.bucketsPanic != "" {
+			report("creation will panic: %s", m.bucketsPanic)
+		}
+		for i := 1; i < len(m.buckets); i++ {
+			if m.buckets[i-1] >= m.buckets[i] {
+				report("creation will panic: histogram buckets must be in increasing order: %f >= %f",
+					m.buckets[i-1], m.buckets[i])
+				break
+			}
+		}
+	}
+}
+
+// parseBuckets evaluates the buckets of a histogram, either a literal or
+// one of the bucket generators with literal arguments. It returns the
+// panic message of the generators for invalid arguments.
+func parseBuckets(n ast.Expr) ([]float64, string) {
+	switch t := n.(type) {
+	case *ast.CompositeLit:
+		buckets := make([]float64, 0, len(t.Elts))
+		for _, elt := range t.Elts {
+			f, ok := parseFloat(elt)
+			if !ok {
+				return nil, ""
+			}
+			buckets = append(buckets, f)
+		}
+		return buckets, ""
+
+	case *ast.Ident:
+		if rhs := aliasOf(t); rhs != nil {
+			return parseBuckets(rhs)
+		}
+
+	case *ast.CallExpr:
+		name := callName(t)
+		if (name != "LinearBuckets" && name != "ExponentialBuckets") || len(t.Args) != 3 {
+			return nil, ""
+		}
+
+		var args [3]float64
+		for i, arg := range t.Args {
+			f, ok := parseFloat(arg)
+			if !ok {
+				return nil, ""
+			}
+			args[i] = f
+		}
+		// The buckets are only checked for sane counts, so a literal can't
+		// make the linter allocate without bound.
+		if args[2] != math.Trunc(args[2]) || args[2] > maxBuckets {
+			return nil, ""
+		}
+		start, step, count := args[0], args[1], int(args[2])
+
+		if count < 1 {
+			return nil, fmt.Sprintf("%s needs a positive count", name)
+		}
+
+		buckets := make([]float64, count)
+		if name == "LinearBuckets" {
+			for i := range buckets {
+				buckets[i] = start
+				start += step
+			}
+			return buckets, ""
+		}
+
+		if start <= 0 {
+			return nil, "ExponentialBuckets needs a positive start value"
+		}
+		if step <= 1 {
+			return nil, "ExponentialBuckets needs a factor greater than 1"
+		}
+		for i := range buckets {
+			buckets[i] = start
+			start *= step
+		}
+		return buckets, ""
+	}
+
+	return nil, ""
+}
+
+// maxBuckets is the max count of LinearBuckets and ExponentialBuckets whose
+// buckets are checked.
+const maxBuckets = 10000
+
+func parseFloat(n ast.Expr) (float64, bool) {
+	switch t := n.(type) {
+	case *ast.BasicLit:
+		if t.Kind != token.INT && t.Kind != token.FLOAT {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(t.Value, 64)
+		return f, err == nil
+
+	case *ast.UnaryExpr:
+		if t.Op != token.SUB {
+			return 0, false
+		}
+		f, ok := parseFloat(t.X)
+		return -f, ok
+
+	case *ast.ParenExpr:
+		return parseFloat(t.X)
+	}
+
+	return 0, false
+}
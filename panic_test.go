@@ -0,0 +1,90 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWillPanic(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "panic.go", `package foo
+
+var (
+	_ = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "request_duration_seconds",
+		Help:    "Request duration.",
+		Buckets: []float64{0.1, 1, 0.5},
+	}, []string{"le"})
+
+	_ = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:        "response_size_bytes",
+		Help:        "Response size.",
+		ConstLabels: prometheus.Labels{"quantile": "0.5", "__meta": "x"},
+	}, []string{"code", "code"})
+
+	_ = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "queue_wait_seconds",
+		Help:    "Queue wait.",
+		Buckets: prometheus.ExponentialBuckets(0, 2, 10),
+	})
+
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "build_info",
+		Help:        "Build info.",
+		ConstLabels: prometheus.Labels{"version": "1.0"},
+	})
+
+	_ = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "batch_size",
+		Help:    "Batch size.",
+		Buckets: prometheus.LinearBuckets(1, 1, 5),
+	})
+)
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(prometheus.NewDesc(
+		"cache-size", "Cache size.", []string{"shard"}, prometheus.Labels{"shard": "a"},
+	), prometheus.GaugeValue, 1, "b")
+}
+`)
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{})
+	texts := issueTexts(issues)
+	assert.ElementsMatch(t, []string{
+		`request_duration_seconds: creation will panic: "le" is not allowed as label name in histograms`,
+		"request_duration_seconds: creation will panic: histogram buckets must be in increasing order: 1.000000 >= 0.500000",
+		`response_size_bytes: registration will panic: duplicate label name "code"`,
+		`response_size_bytes: registration will panic: "__meta" is not a valid label name`,
+		`response_size_bytes: creation will panic: "quantile" is not allowed as label name in summaries`,
+		"queue_wait_seconds: creation will panic: ExponentialBuckets needs a positive start value",
+		`cache-size: registration will panic: "cache-size" is not a valid metric name`,
+		`cache-size: registration will panic: duplicate label name "shard"`,
+	}, texts)
+}
+
+func TestWillPanicBucketCount(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "buckets.go", `package foo
+
+var (
+	_ = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "huge_seconds",
+		Help:    "Huge.",
+		Buckets: prometheus.LinearBuckets(0, 1, 1e12),
+	})
+	_ = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "fraction_seconds",
+		Help:    "Fraction.",
+		Buckets: prometheus.ExponentialBuckets(0, 2, 2.5),
+	})
+)
+`)
+
+	// The buckets aren't allocated, nor checked.
+	for _, iss := range RunLint(fs, []*ast.File{file}, Setting{}) {
+		assert.NotContains(t, iss.Text, "will panic")
+	}
+}
@@ -0,0 +1,71 @@
+package promlinter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	// labelSegmentRE matches name segments which should be labels, e.g.
+	// requests_for_user_alice_total.
+	labelSegmentRE = regexp.MustCompile(`_(for|by)_[a-z0-9]`)
+	// trailingNumberRE matches names ending with a bare number, e.g. shard_3.
+	trailingNumberRE = regexp.MustCompile(`_[0-9]+$`)
+)
+
+// lintBestPractices reports names breaking the Prometheus naming best
+// practices which promlint doesn't check.
+func (v *visitor) lintBestPractices() {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		name, typ := m.MetricFamily.GetName(), m.MetricFamily.GetType()
+
+		var texts []string
+		if strings.HasSuffix(name, "_per_second") && typ == dto.MetricType_GAUGE {
+			texts = append(texts, "gauges named *_per_second should be counters used with rate()")
+		}
+		if strings.HasSuffix(name, "_info") && typ != dto.MetricType_GAUGE && typ != MetricTypeInfo {
+			texts = append(texts, "info metrics should be gauges with a constant value of 1")
+		}
+		if strings.HasSuffix(strings.TrimSuffix(name, "_total"), "_timestamp_seconds") && typ != dto.MetricType_GAUGE {
+			texts = append(texts, "timestamps should be gauges")
+		}
+		if strings.HasSuffix(strings.TrimSuffix(name, "_total"), "_created") {
+			texts = append(texts, "metric names should not end with _created, "+
+				"it is reserved for the creation time of counters, histograms and summaries")
+		}
+		if match := labelSegmentRE.FindStringSubmatch(name); match != nil {
+			texts = append(texts, fmt.Sprintf("metric name contains the label-like segment %q, use a label instead", match[1]))
+		}
+		if trailingNumberRE.MatchString(name) {
+			texts = append(texts, "metric name should not end with a number, use a label instead")
+		}
+
+		v.appendIssues(m, texts)
+	}
+
+	// Info metrics are only ever set to 1.
+	for _, c := range v.calls {
+		if !updateMethods[c.method] {
+			continue
+		}
+		m, _ := v.resolve(c)
+		if m == nil || !strings.HasSuffix(m.MetricFamily.GetName(), "_info") {
+			continue
+		}
+		if c.method == "Set" && len(c.args) == 1 {
+			if f, ok := parseFloat(c.args[0]); !ok || f == 1 {
+				continue
+			}
+		}
+
+		v.issues = append(v.issues, Issue{
+			Pos:    c.pos,
+			Metric: m.MetricFamily.GetName(),
+			Text:   fmt.Sprintf("info metrics should have a constant value of 1, the metric is updated with %s", c.method),
+		})
+	}
+}
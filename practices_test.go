@@ -0,0 +1,51 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBestPractices(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "practices.go", `package foo
+
+var (
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "requests_per_second", Help: "Requests per second."})
+	build = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "build_info", Help: "Build information."}, []string{"version"})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "version_info", Help: "Version information."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "last_run_timestamp_seconds_total", Help: "Last run time."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "job_created", Help: "Job creation time."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_for_user_alice_total", Help: "Requests of alice."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "shard_size_bytes_3", Help: "Size of shard 3."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "last_run_timestamp_seconds", Help: "Last run time."})
+
+	_ = []generator.FamilyGenerator{
+		*generator.NewFamilyGenerator("kube_pod_info", "Pod information.", metric.Info, "", nil),
+	}
+)
+
+func update() {
+	build.WithLabelValues("v1").Set(1)
+	build.WithLabelValues("v2").Inc()
+}
+`)
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{
+		DisabledLintFuncs: []string{"Counter"},
+		EnabledLintFuncs:  []string{"BestPractices"},
+	})
+	texts := issueTexts(issues)
+	assert.Equal(t, []string{
+		"requests_per_second: gauges named *_per_second should be counters used with rate()",
+		"version_info: info metrics should be gauges with a constant value of 1",
+		"last_run_timestamp_seconds_total: timestamps should be gauges",
+		"job_created: metric names should not end with _created, " +
+			"it is reserved for the creation time of counters, histograms and summaries",
+		`requests_for_user_alice_total: metric name contains the label-like segment "for", use a label instead`,
+		"shard_size_bytes_3: metric name should not end with a number, use a label instead",
+		"build_info: info metrics should have a constant value of 1, the metric is updated with Inc",
+	}, texts)
+}
@@ -0,0 +1,204 @@
+package promlinter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// The rules of promlint from client_golang, reimplemented to report
+// their rule IDs. The texts are kept identical.
+
+// lintHelp detects issues related to the help text for a metric.
+func lintHelp(mf *dto.MetricFamily) []string {
+	if mf.Help == nil {
+		return []string{"no help text"}
+	}
+	return nil
+}
+
+// lintMetricUnits detects issues with metric unit names.
+func lintMetricUnits(mf *dto.MetricFamily) []string {
+	unit, base, ok := metricUnits(mf.GetName())
+	if !ok || unit == base {
+		return nil
+	}
+	return []string{fmt.Sprintf("use base unit %q instead of %q", base, unit)}
+}
+
+// lintCounter detects issues specific to counters, as well as patterns that
+// should only be used with counters.
+func lintCounter(mf *dto.MetricFamily) []string {
+	isCounter := mf.GetType() == dto.MetricType_COUNTER
+	isUntyped := mf.GetType() == dto.MetricType_UNTYPED
+	hasTotalSuffix := strings.HasSuffix(mf.GetName(), "_total")
+
+	switch {
+	case isCounter && !hasTotalSuffix:
+		return []string{`counter metrics should have "_total" suffix`}
+	case !isUntyped && !isCounter && hasTotalSuffix:
+		return []string{`non-counter metrics should not have "_total" suffix`}
+	}
+	return nil
+}
+
+// lintHistogramSummaryReserved detects when other types of metrics use names
+// or labels reserved for use by histograms and/or summaries.
+func lintHistogramSummaryReserved(mf *dto.MetricFamily) []string {
+	// These rules do not apply to untyped metrics.
+	t := mf.GetType()
+	if t == dto.MetricType_UNTYPED {
+		return nil
+	}
+
+	var texts []string
+	isHistogram := t == dto.MetricType_HISTOGRAM
+	isSummary := t == dto.MetricType_SUMMARY
+	n := mf.GetName()
+
+	if !isHistogram && strings.HasSuffix(n, "_bucket") {
+		texts = append(texts, `non-histogram metrics should not have "_bucket" suffix`)
+	}
+	if !isHistogram && !isSummary && strings.HasSuffix(n, "_count") {
+		texts = append(texts, `non-histogram and non-summary metrics should not have "_count" suffix`)
+	}
+	if !isHistogram && !isSummary && strings.HasSuffix(n, "_sum") {
+		texts = append(texts, `non-histogram and non-summary metrics should not have "_sum" suffix`)
+	}
+
+	for _, m := range mf.GetMetric() {
+		for _, l := range m.GetLabel() {
+			ln := l.GetName()
+			if !isHistogram && ln == "le" {
+				texts = append(texts, `non-histogram metrics should not have "le" label`)
+			}
+			if !isSummary && ln == "quantile" {
+				texts = append(texts, `non-summary metrics should not have "quantile" label`)
+			}
+		}
+	}
+	return texts
+}
+
+// typeNamesInName are the metric types which shouldn't be in the metric name.
+var typeNamesInName = []string{"counter", "gauge", "summary", "histogram"}
+
+// lintMetricTypeInName detects when metric types are included in the metric name.
+func lintMetricTypeInName(mf *dto.MetricFamily) []string {
+	var texts []string
+	n := strings.ToLower(mf.GetName())
+	for _, typename := range typeNamesInName {
+		if strings.Contains(n, "_"+typename+"_") || strings.HasSuffix(n, "_"+typename) {
+			texts = append(texts, fmt.Sprintf(`metric name should not include type '%s'`, typename))
+		}
+	}
+	return texts
+}
+
+// lintReservedChars detects colons in metric names.
+func lintReservedChars(mf *dto.MetricFamily) []string {
+	if strings.Contains(mf.GetName(), ":") {
+		return []string{"metric names should not contain ':'"}
+	}
+	return nil
+}
+
+var camelCase = regexp.MustCompile(`[a-z][A-Z]`)
+
+// lintCamelCase detects metric names and label names written in camelCase.
+func lintCamelCase(mf *dto.MetricFamily) []string {
+	var texts []string
+	if camelCase.FindString(mf.GetName()) != "" {
+		texts = append(texts, "metric names should be written in 'snake_case' not 'camelCase'")
+	}
+
+	for _, m := range mf.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if camelCase.FindString(l.GetName()) != "" {
+				texts = append(texts, "label names should be written in 'snake_case' not 'camelCase'")
+			}
+		}
+	}
+	return texts
+}
+
+// lintUnitAbbreviations detects abbreviated units in the metric name.
+func lintUnitAbbreviations(mf *dto.MetricFamily) []string {
+	var texts []string
+	n := strings.ToLower(mf.GetName())
+	for _, s := range unitAbbreviations {
+		if strings.Contains(n, "_"+s+"_") || strings.HasSuffix(n, "_"+s) {
+			texts = append(texts, "metric names should not contain abbreviated units")
+		}
+	}
+	return texts
+}
+
+// metricUnits detects the first known unit used as part of a metric name,
+// e.g. "foo_bytes_total" or "bar_baz_milligrams".
+func metricUnits(m string) (unit string, base string, ok bool) {
+	for _, s := range strings.Split(m, "_") {
+		// Match a known unit with a known prefix explicitly, as some words
+		// look like units when matching the suffix, e.g. "thermometers".
+		for _, p := range append(unitPrefixes, "") {
+			if !strings.HasPrefix(s, p) {
+				continue
+			}
+			if base, ok := baseUnits[s[len(p):]]; ok {
+				return s, base, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+var (
+	// baseUnits maps the units to their base unit.
+	baseUnits = map[string]string{
+		// Base units.
+		"amperes": "amperes",
+		"bytes":   "bytes",
+		"celsius": "celsius", // Also allow Celsius because it is common in typical Prometheus use cases.
+		"grams":   "grams",
+		"joules":  "joules",
+		"kelvin":  "kelvin", // SI base unit, used in special cases (e.g. color temperature, scientific measurements).
+		"meters":  "meters", // Both American and international spelling permitted.
+		"metres":  "metres",
+		"seconds": "seconds",
+		"volts":   "volts",
+
+		// Non base units.
+		// Time.
+		"minutes": "seconds",
+		"hours":   "seconds",
+		"days":    "seconds",
+		"weeks":   "seconds",
+		// Temperature.
+		"kelvins":    "kelvin",
+		"fahrenheit": "celsius",
+		"rankine":    "celsius",
+		// Length.
+		"inches": "meters",
+		"yards":  "meters",
+		"miles":  "meters",
+		// Bytes.
+		"bits": "bytes",
+		// Energy.
+		"calories": "joules",
+		// Mass.
+		"pounds": "grams",
+		"ounces": "grams",
+	}
+
+	unitPrefixes = []string{
+		"pico", "nano", "micro", "milli", "centi", "deci", "deca", "hecto",
+		"kilo", "kibi", "mega", "mibi", "giga", "gibi", "tera", "tebi", "peta", "pebi",
+	}
+
+	// Common abbreviations that we'd like to discourage.
+	unitAbbreviations = []string{
+		"s", "ms", "us", "ns", "sec", "b", "kb", "mb", "gb", "tb", "pb", "m", "h", "d",
+	}
+)
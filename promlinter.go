@@ -4,21 +4,25 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"hash/fnv"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
 	dto "github.com/prometheus/client_model/go"
 )
 
 var (
 	metricsType       map[string]dto.MetricType
+	ksmMetricsType    map[string]dto.MetricType
 	constMetricArgNum map[string]int
 	validOptsFields   map[string]bool
-	lintFuncText      map[string][]string
-	LintFuncNames     []string
+	// LintFuncNames are the IDs of the rules which run by default.
+	LintFuncNames []string
+	// OptionalLintFuncNames are the IDs of the rules which only run when enabled.
+	OptionalLintFuncNames []string
 )
 
 func init() {
@@ -35,6 +39,14 @@ func init() {
 		"NewSummaryVec":   dto.MetricType_SUMMARY,
 	}
 
+	// The metric types of kube-state-metrics' NewFamilyGenerator.
+	ksmMetricsType = map[string]dto.MetricType{
+		"Counter":  dto.MetricType_COUNTER,
+		"Gauge":    dto.MetricType_GAUGE,
+		"Info":     MetricTypeInfo,
+		"StateSet": MetricTypeStateSet,
+	}
+
 	constMetricArgNum = map[string]int{
 		"MustNewConstMetric": 3,
 		"MustNewHistogram":   4,
@@ -42,32 +54,50 @@ func init() {
 		"NewLazyConstMetric": 3,
 	}
 
-	// Doesn't contain ConstLabels since we don't need this field here.
+	// Doesn't contain ConstLabels and Buckets since they aren't string literals.
 	validOptsFields = map[string]bool{
 		"Name":      true,
 		"Namespace": true,
 		"Subsystem": true,
 		"Help":      true,
 	}
-
-	lintFuncText = map[string][]string{
-		"Help":                     {"no help text"},
-		"MetricUnits":              {"use base unit"},
-		"Counter":                  {"counter metrics should"},
-		"HistogramSummaryReserved": {"non-histogram", "non-summary"},
-		"MetricTypeInName":         {"metric name should not include type"},
-		"ReservedChars":            {"metric names should not contain ':'"},
-		"CamelCase":                {"'snake_case' not 'camelCase'"},
-		"lintUnitAbbreviations":    {"metric names should not contain abbreviated units"},
-	}
-
-	LintFuncNames = []string{"Help", "MetricUnits", "Counter", "HistogramSummaryReserved",
-		"MetricTypeInName", "ReservedChars", "CamelCase", "lintUnitAbbreviations"}
 }
 
 type Setting struct {
-	Strict            bool
-	DisabledLintFuncs []string
+	Strict            bool     `yaml:"strict"`
+	DisabledLintFuncs []string `yaml:"disable"`
+	// EnabledLintFuncs are the optional lint functions to run.
+	EnabledLintFuncs []string `yaml:"enable"`
+	// OpenMetrics checks the OpenMetrics conventions too, for metrics
+	// exposed with the OpenMetrics format.
+	OpenMetrics bool `yaml:"openmetrics"`
+	// NameValidation is the validation scheme of the metric and label
+	// names, NameValidationLegacy if empty.
+	NameValidation string `yaml:"nameValidation"`
+
+	Libraries LibrarySetting `yaml:"libraries"`
+	// EntityLabels are the label names identifying transient entities,
+	// which are deleted from vectors when the entity goes away.
+	EntityLabels []string `yaml:"entityLabels"`
+	// Synonyms are groups of metric name tokens meaning the same, the
+	// first token of a group being the canonical one.
+	Synonyms [][]string `yaml:"synonyms"`
+	// LabelVocabulary maps the canonical label names to their forbidden synonyms.
+	LabelVocabulary map[string][]string `yaml:"labelVocabulary"`
+	// Namespaces are the namespace policies, the first one applying to a
+	// package is used.
+	Namespaces []NamespacePolicy `yaml:"namespaces"`
+	// Help configures the help text rules.
+	Help HelpSetting `yaml:"help"`
+	// Units is the unit policy of the metric names.
+	Units UnitSetting `yaml:"units"`
+	// Labels configures the label name rules.
+	Labels LabelSetting `yaml:"labels"`
+	// Severities override the default severity of the rules, by rule ID.
+	Severities map[string]Severity `yaml:"severities"`
+	// Changed, if set, restricts the issues to the ones on changed lines,
+	// and on metrics whose declarations have changed lines.
+	Changed ChangedLines `yaml:"-"`
 }
 
 // Issue contains metric name, error text and metric position.
@@ -75,11 +105,49 @@ type Issue struct {
 	Text   string
 	Metric string
 	Pos    token.Position
+	// RuleID is the ID of the rule reporting the issue.
+	RuleID string
+	// Severity is the severity of the rule, as set in the setting.
+	Severity Severity
 }
 
 type MetricFamilyWithPos struct {
 	MetricFamily *dto.MetricFamily
-	Pos          token.Position
+	// Pos is the first position the metric family is declared at, and
+	// Positions all of them.
+	Pos       token.Position
+	Positions []token.Position
+
+	// Namespace and Subsystem the name is built from, if known.
+	Namespace string
+	Subsystem string
+
+	// Updates are the positions of the calls updating the metric, and
+	// Deletes the ones deleting series of a vector.
+	Updates []token.Position
+	Deletes []token.Position
+
+	// constructor is the name of the function that created the metric,
+	// and pkg the name of the package it is created in.
+	constructor string
+	pkg         string
+	// bindings are the variables or fields the metric is assigned to.
+	bindings []binding
+	// registerers are the registerers the metric is registered on.
+	registerers []binding
+	// collected is set if the metric is collected by a custom collector.
+	collected bool
+	// declarations are the files and lines of the calls declaring the metric.
+	declarations []declaration
+
+	// buckets of a histogram, and the reason creating it panics.
+	buckets      []float64
+	bucketsPanic string
+}
+
+// IsVector reports whether the metric is a vector with variable labels.
+func (m *MetricFamilyWithPos) IsVector() bool {
+	return strings.HasSuffix(m.constructor, "Vec")
 }
 
 func (m *MetricFamilyWithPos) Labels() []string {
@@ -102,34 +170,97 @@ func (m *MetricFamilyWithPos) Labels() []string {
 type visitor struct {
 	fs      *token.FileSet
 	metrics []MetricFamilyWithPos
-	issues  []Issue
-	strict  bool
+	// index maps the hash of a metric family to its index in metrics.
+	index  map[uint64]int
+	issues []Issue
+	strict bool
+	// utf8Names is set if the names are validated with NameValidationUTF8.
+	utf8Names bool
+
+	// dir, pkg and imports describe the file being walked.
+	dir     string
+	pkg     string
+	imports map[string]string
+
+	bindings        map[*ast.CallExpr]string
+	chained         map[*ast.CallExpr]bool
+	calls           []metricCall
+	registrations   []registration
+	registeredCalls map[*ast.CallExpr]string
+	registries      []registry
+	exposures       []binding
+	suppressions    []suppression
+	// descs are the NewDesc calls by the variable or field they are assigned to.
+	descs map[binding][]*ast.CallExpr
+
+	// byBinding and byBindingName map the bindings of the metrics, and
+	// their names in any directory, to the index of the first metric bound
+	// to them. byPos maps the declaration positions to the indexes of the
+	// metrics declared there.
+	byBinding     map[binding]int
+	byBindingName map[string]int
+	byPos         map[token.Position][]int
 }
 
 type opt struct {
 	namespace string
 	subsystem string
 	name      string
+	// nameNamespace and nameSubsystem are set when the name is built by BuildFQName.
+	nameNamespace string
+	nameSubsystem string
 
 	help    string
 	helpSet bool
 
 	labels      []string
 	constLabels map[string]string
+
+	// buckets of a histogram, and the reason creating it panics.
+	buckets      []float64
+	bucketsPanic string
 }
 
-func RunList(fs *token.FileSet, files []*ast.File, strict bool) []MetricFamilyWithPos {
-	v := &visitor{
-		fs:      fs,
-		metrics: make([]MetricFamilyWithPos, 0),
-		issues:  make([]Issue, 0),
-		strict:  strict,
+func newVisitor(fs *token.FileSet, strict bool) *visitor {
+	return &visitor{
+		fs:       fs,
+		metrics:  make([]MetricFamilyWithPos, 0),
+		index:    make(map[uint64]int),
+		issues:   make([]Issue, 0),
+		strict:   strict,
+		bindings: make(map[*ast.CallExpr]string),
+		chained:  make(map[*ast.CallExpr]bool),
+
+		byBinding:     make(map[binding]int),
+		byBindingName: make(map[string]int),
+		byPos:         make(map[token.Position][]int),
+
+		registeredCalls: make(map[*ast.CallExpr]string),
+		descs:           make(map[binding][]*ast.CallExpr),
 	}
+}
 
+func (v *visitor) walk(files []*ast.File) {
+	for _, file := range files {
+		v.collectDescs(file, filepath.Dir(v.fs.Position(file.Pos()).Filename))
+	}
 	for _, file := range files {
+		v.dir = filepath.Dir(v.fs.Position(file.Pos()).Filename)
+		v.pkg = file.Name.Name
+		v.imports = fileImports(file)
+		v.collectSuppressions(file)
 		ast.Walk(v, file)
 	}
+}
+
+func RunList(fs *token.FileSet, files []*ast.File, strict bool) []MetricFamilyWithPos {
+	v := newVisitor(fs, strict)
+	v.walk(files)
 
+	// The calls are resolved before the metrics are sorted, as they are
+	// indexed in the order they are declared in.
+	v.resolveCalls()
+	v.sortPositions()
 	sort.Slice(v.metrics, func(i, j int) bool {
 		return v.metrics[i].Pos.String() < v.metrics[j].Pos.String()
 	})
@@ -137,44 +268,81 @@ func RunList(fs *token.FileSet, files []*ast.File, strict bool) []MetricFamilyWi
 }
 
 func RunLint(fs *token.FileSet, files []*ast.File, s Setting) []Issue {
-	v := &visitor{
-		fs:      fs,
-		metrics: make([]MetricFamilyWithPos, 0),
-		issues:  make([]Issue, 0),
-		strict:  s.Strict,
+	v := newVisitor(fs, s.Strict)
+	v.utf8Names = s.NameValidation == NameValidationUTF8
+	v.walk(files)
+	v.sortPositions()
+	v.resolveCalls()
+
+	// Parsing failures are reported while walking the files.
+	for i := range v.issues {
+		v.issues[i].RuleID = parsingRule
 	}
 
-	for _, file := range files {
-		ast.Walk(v, file)
+	ran := map[string]bool{}
+	for _, r := range Rules {
+		if r.Optional && !s.isEnabled(r.ID) || !r.Optional && s.isDisabled(r.ID) {
+			continue
+		}
+		ran[r.ID] = true
+
+		n := len(v.issues)
+		r.lint(v, s)
+		for i := n; i < len(v.issues); i++ {
+			v.issues[i].RuleID = r.ID
+		}
 	}
+	ran[parsingRule] = ran[parsingRule] && s.Strict
 
-	// lint metrics
-	for _, mfp := range v.metrics {
-		problems, err := promlint.NewWithMetricFamilies([]*dto.MetricFamily{mfp.MetricFamily}).Lint()
-		if err != nil {
-			panic(err)
+	issues := v.issues[:0]
+	for _, iss := range v.issues {
+		if iss.RuleID != parsingRule || ran[parsingRule] {
+			issues = append(issues, iss)
+		}
+	}
+	v.issues = v.suppress(issues)
+
+	// The suppressions are checked once applied to the issues of all the
+	// other rules.
+	if ran[suppressionRule] {
+		n := len(v.issues)
+		v.lintSuppressions(ran)
+		for i := n; i < len(v.issues); i++ {
+			v.issues[i].RuleID = suppressionRule
 		}
+	}
 
-		for _, p := range problems {
-			for _, disabledFunc := range s.DisabledLintFuncs {
-				for _, pattern := range lintFuncText[disabledFunc] {
-					if strings.Contains(p.Text, pattern) {
-						goto END
-					}
-				}
-			}
+	for i := range v.issues {
+		v.issues[i].Severity = s.severity(rulesByID[v.issues[i].RuleID])
+	}
+	if s.Changed != nil {
+		return v.changedIssues(s.Changed)
+	}
+	return v.issues
+}
 
-			v.issues = append(v.issues, Issue{
-				Pos:    mfp.Pos,
-				Metric: p.Metric,
-				Text:   p.Text,
-			})
+// sortPositions orders the declaration positions of each metric, so Pos is
+// the first one regardless of the order the files are walked in.
+func (v *visitor) sortPositions() {
+	for i := range v.metrics {
+		positions := v.metrics[i].Positions
+		sort.Slice(positions, func(i, j int) bool {
+			if positions[i].Filename != positions[j].Filename {
+				return positions[i].Filename < positions[j].Filename
+			}
+			return positions[i].Offset < positions[j].Offset
+		})
+		v.metrics[i].Pos = positions[0]
+	}
+}
 
-		END:
+func (s Setting) isDisabled(name string) bool {
+	for _, disabledFunc := range s.DisabledLintFuncs {
+		if ruleID(disabledFunc) == name {
+			return true
 		}
 	}
-
-	return v.issues
+	return false
 }
 
 func (v *visitor) Visit(n ast.Node) ast.Visitor {
@@ -184,8 +352,30 @@ func (v *visitor) Visit(n ast.Node) ast.Visitor {
 
 	switch t := n.(type) {
 	case *ast.CallExpr:
+		v.parseMetricCall(t)
+		v.parseRegisterCall(t)
 		return v.parseCallerExpr(t)
 
+	case *ast.TypeAssertExpr:
+		v.parseTypeAssertExpr(t)
+
+	case *ast.AssignStmt:
+		if len(t.Lhs) == len(t.Rhs) {
+			for i := range t.Lhs {
+				v.bind(t.Lhs[i], t.Rhs[i])
+			}
+		}
+
+	case *ast.ValueSpec:
+		for i := range t.Names {
+			if i < len(t.Values) {
+				v.bind(t.Names[i], t.Values[i])
+			}
+		}
+
+	case *ast.KeyValueExpr:
+		v.bind(t.Key, t.Value)
+
 	case *ast.SendStmt:
 		return v.parseSendMetricChanExpr(t)
 
@@ -195,14 +385,90 @@ func (v *visitor) Visit(n ast.Node) ast.Visitor {
 	return v
 }
 
-func (v *visitor) addMetric(mfp *MetricFamilyWithPos) {
-	for _, m := range v.metrics {
-		if mfp.MetricFamily.String() == m.MetricFamily.String() {
-			return
+func (v *visitor) addMetric(mfp *MetricFamilyWithPos, call *ast.CallExpr) {
+	mfp.constructor = callName(call)
+	mfp.pkg = v.pkg
+	if registerer := v.promautoRegisterer(call); registerer != "" {
+		v.registrations = append(v.registrations, registration{
+			registerer: registerer,
+			promauto:   true,
+			metric:     mfp.MetricFamily.GetName(),
+			pkg:        v.pkg,
+			dir:        v.dir,
+			pos:        v.fs.Position(call.Pos()),
+		})
+		mfp.registerers = append(mfp.registerers, binding{dir: v.dir, name: registerer})
+	}
+	if registerer, ok := v.registeredCalls[call]; ok {
+		mfp.registerers = append(mfp.registerers, binding{dir: v.dir, name: registerer})
+	}
+	if name, ok := v.bindings[call]; ok {
+		mfp.bindings = append(mfp.bindings, binding{dir: v.dir, name: name})
+	}
+
+	mfp.Positions = []token.Position{mfp.Pos}
+	// The declarations passed, e.g. of the NewDesc call of a const metric,
+	// follow the call creating the metric.
+	mfp.declarations = append([]declaration{v.declarationOf(call)}, mfp.declarations...)
+
+	// The same metric family may legitimately be declared in several
+	// places, e.g. a Desc sent from two Collect paths, so all the
+	// declaration positions are kept.
+	h := hashMetricFamily(mfp.MetricFamily)
+	i, ok := v.index[h]
+	if ok {
+		v.metrics[i].Positions = append(v.metrics[i].Positions, mfp.Pos)
+		v.metrics[i].declarations = append(v.metrics[i].declarations, mfp.declarations...)
+		v.metrics[i].bindings = append(v.metrics[i].bindings, mfp.bindings...)
+		v.metrics[i].registerers = append(v.metrics[i].registerers, mfp.registerers...)
+	} else {
+		i = len(v.metrics)
+		v.index[h] = i
+		v.metrics = append(v.metrics, *mfp)
+	}
+
+	// The metrics are looked up in the order they are declared in.
+	for _, b := range mfp.bindings {
+		if j, ok := v.byBinding[b]; !ok || i < j {
+			v.byBinding[b] = i
 		}
+		if j, ok := v.byBindingName[b.name]; !ok || i < j {
+			v.byBindingName[b.name] = i
+		}
+	}
+	v.byPos[mfp.Pos] = append(v.byPos[mfp.Pos], i)
+}
+
+// hashMetricFamily returns a hash of the name, type, help and labels of the
+// metric family.
+func hashMetricFamily(mf *dto.MetricFamily) uint64 {
+	h := fnv.New64a()
+	write := func(s *string) {
+		if s == nil {
+			h.Write([]byte{0})
+		} else {
+			h.Write([]byte{1})
+			h.Write([]byte(*s))
+		}
+		h.Write([]byte{0xff})
 	}
 
-	v.metrics = append(v.metrics, *mfp)
+	write(mf.Name)
+	write(mf.Help)
+	if mf.Type != nil {
+		typ := metricTypeName(*mf.Type)
+		write(&typ)
+	} else {
+		write(nil)
+	}
+	for _, m := range mf.Metric {
+		for _, label := range m.Label {
+			write(label.Name)
+			write(label.Value)
+		}
+		h.Write([]byte{0xfe})
+	}
+	return h.Sum64()
 }
 
 func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
@@ -222,11 +488,11 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 	*/
 	case *ast.Ident:
 		if stmt.Name == "NewCounterFunc" {
-			return v.parseOpts(call.Args, dto.MetricType_COUNTER)
+			return v.parseOpts(call, dto.MetricType_COUNTER)
 		}
 
 		if stmt.Name == "NewGaugeFunc" {
-			return v.parseOpts(call.Args, dto.MetricType_GAUGE)
+			return v.parseOpts(call, dto.MetricType_GAUGE)
 		}
 
 		if metricType, ok = metricsType[stmt.Name]; !ok {
@@ -249,15 +515,15 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 	*/
 	case *ast.SelectorExpr:
 		if stmt.Sel.Name == "NewCounterFunc" {
-			return v.parseOpts(call.Args, dto.MetricType_COUNTER)
+			return v.parseOpts(call, dto.MetricType_COUNTER)
 		}
 
 		if stmt.Sel.Name == "NewGaugeFunc" {
-			return v.parseOpts(call.Args, dto.MetricType_GAUGE)
+			return v.parseOpts(call, dto.MetricType_GAUGE)
 		}
 
 		if stmt.Sel.Name == "NewFamilyGenerator" && len(call.Args) == 5 {
-			return v.parseKSMMetrics(call.Args[0], call.Args[1], call.Args[2])
+			return v.parseKSMMetrics(call)
 		}
 
 		if metricType, ok = metricsType[stmt.Sel.Name]; !ok {
@@ -288,10 +554,11 @@ func (v *visitor) parseCallerExpr(call *ast.CallExpr) ast.Visitor {
 		return v
 	}
 
-	return v.parseOpts(call.Args, metricType)
+	return v.parseOpts(call, metricType)
 }
 
-func (v *visitor) parseOpts(optArgs []ast.Expr, metricType dto.MetricType) ast.Visitor {
+func (v *visitor) parseOpts(call *ast.CallExpr, metricType dto.MetricType) ast.Visitor {
+	optArgs := call.Args
 	// position for the first arg of the CallExpr
 	optsPosition := v.fs.Position(optArgs[0].Pos())
 	opts := v.parseOptsExpr(optArgs[0])
@@ -324,6 +591,20 @@ func (v *visitor) parseOpts(optArgs []ast.Expr, metricType dto.MetricType) ast.V
 		currentMetric.Help = &opts.help
 	}
 
+	if len(opts.constLabels) > 0 {
+		if metric == nil {
+			metric = &dto.Metric{}
+		}
+		for _, l := range sortedConstLabels(opts.constLabels) {
+			name, value := l[0], l[1]
+			metric.Label = append(metric.Label,
+				&dto.LabelPair{
+					Name:  &name,
+					Value: &value,
+				})
+		}
+	}
+
 	if metric != nil {
 		currentMetric.Metric = append(currentMetric.Metric, metric)
 	}
@@ -337,12 +618,25 @@ func (v *visitor) parseOpts(optArgs []ast.Expr, metricType dto.MetricType) ast.V
 	}
 	currentMetric.Name = &metricName
 
-	v.addMetric(&MetricFamilyWithPos{MetricFamily: &currentMetric, Pos: optsPosition})
+	namespace, subsystem := opts.namespace, opts.subsystem
+	if namespace == "" && subsystem == "" {
+		namespace, subsystem = opts.nameNamespace, opts.nameSubsystem
+	}
+
+	v.addMetric(&MetricFamilyWithPos{
+		MetricFamily: &currentMetric,
+		Pos:          optsPosition,
+		Namespace:    namespace,
+		Subsystem:    subsystem,
+		buckets:      opts.buckets,
+		bucketsPanic: opts.bucketsPanic,
+	}, call)
 	return v
 }
 
 // Parser for kube-state-metrics generators.
-func (v *visitor) parseKSMMetrics(nameArg ast.Node, helpArg ast.Node, metricTypeArg ast.Node) ast.Visitor {
+func (v *visitor) parseKSMMetrics(call *ast.CallExpr) ast.Visitor {
+	nameArg, helpArg, metricTypeArg := call.Args[0], call.Args[1], call.Args[2]
 	optsPosition := v.fs.Position(nameArg.Pos())
 	currentMetric := dto.MetricFamily{}
 	name, ok := v.parseValue("name", nameArg)
@@ -359,14 +653,14 @@ func (v *visitor) parseKSMMetrics(nameArg ast.Node, helpArg ast.Node, metricType
 
 	switch stmt := metricTypeArg.(type) {
 	case *ast.SelectorExpr:
-		if metricType, ok := metricsType[stmt.Sel.Name]; !ok {
+		if metricType, ok := ksmMetricsType[stmt.Sel.Name]; !ok {
 			return v
 		} else {
 			currentMetric.Type = &metricType
 		}
 	}
 
-	v.addMetric(&MetricFamilyWithPos{MetricFamily: &currentMetric, Pos: optsPosition})
+	v.addMetric(&MetricFamilyWithPos{MetricFamily: &currentMetric, Pos: optsPosition}, call)
 
 	return v
 }
@@ -381,6 +675,13 @@ func (v *visitor) parseSendMetricChanExpr(chExpr *ast.SendStmt) ast.Visitor {
 
 	call, ok := chExpr.Value.(*ast.CallExpr)
 	if !ok {
+		// ch <- c.requests
+		v.calls = append(v.calls, metricCall{
+			method: "Collect",
+			recv:   chExpr.Value,
+			dir:    v.dir,
+			pos:    v.fs.Position(chExpr.Pos()),
+		})
 		return v
 	}
 
@@ -421,7 +722,7 @@ func (v *visitor) parseSendMetricChanExpr(chExpr *ast.SendStmt) ast.Visitor {
 		Help: descCall.help,
 	}
 
-	if len(descCall.labels) > 0 {
+	if len(descCall.labels) > 0 || len(descCall.constLabels) > 0 {
 		m := &dto.Metric{}
 		for idx, _ := range descCall.labels {
 			m.Label = append(m.Label,
@@ -458,7 +759,13 @@ func (v *visitor) parseSendMetricChanExpr(chExpr *ast.SendStmt) ast.Visitor {
 		metric.Type = &metricType
 	}
 
-	v.addMetric(&MetricFamilyWithPos{MetricFamily: metric, Pos: v.fs.Position(call.Pos())})
+	v.addMetric(&MetricFamilyWithPos{
+		MetricFamily: metric,
+		Pos:          v.fs.Position(call.Pos()),
+		Namespace:    descCall.namespace,
+		Subsystem:    descCall.subsystem,
+		declarations: []declaration{descCall.decl},
+	}, call)
 	return v
 }
 
@@ -512,12 +819,14 @@ func (v *visitor) parseCompositeOpts(stmt *ast.CompositeLit) *opt {
 			//  {
 			//  	"key": "some-string-literal",
 			//  }
+			// The quotes are removed once here, so the rules see the
+			// label names as registered.
 			switch val := kvExpr.Value.(type) {
 			case *ast.BasicLit:
-				metricOption.constLabels[key.Value] = val.Value
+				metricOption.constLabels[unquote(key.Value)] = unquote(val.Value)
 
 			default:
-				metricOption.constLabels[key.Value] = "?" // use a placeholder for the const label
+				metricOption.constLabels[unquote(key.Value)] = "?" // use a placeholder for the const label
 			}
 
 			continue
@@ -528,6 +837,18 @@ func (v *visitor) parseCompositeOpts(stmt *ast.CompositeLit) *opt {
 			continue
 		}
 
+		switch object.Name {
+		case "ConstLabels":
+			if labelOpts := v.parseOptsExpr(kvExpr.Value); labelOpts != nil {
+				metricOption.constLabels = labelOpts.constLabels
+			}
+			continue
+
+		case "Buckets":
+			metricOption.buckets, metricOption.bucketsPanic = parseBuckets(kvExpr.Value)
+			continue
+		}
+
 		if _, ok := validOptsFields[object.Name]; !ok {
 			continue
 		}
@@ -545,6 +866,7 @@ func (v *visitor) parseCompositeOpts(stmt *ast.CompositeLit) *opt {
 			metricOption.subsystem = stringLiteral
 		case "Name":
 			metricOption.name = stringLiteral
+			metricOption.nameNamespace, metricOption.nameSubsystem = v.parseFQName(kvExpr.Value)
 		case "Help":
 			metricOption.help = stringLiteral
 			metricOption.helpSet = true
@@ -670,12 +992,60 @@ func (v *visitor) parseValueCallExpr(object string, call *ast.CallExpr) (string,
 	return "", false
 }
 
+// parseFQName returns the namespace and subsystem of a name built by
+// BuildFQName, e.g. `prometheus.BuildFQName("foo", "bar", "total")`.
+func (v *visitor) parseFQName(n ast.Expr) (string, string) {
+	switch t := n.(type) {
+	case *ast.Ident:
+		if t.Obj != nil {
+			if vs, ok := t.Obj.Decl.(*ast.ValueSpec); ok && len(vs.Values) > 0 {
+				return v.parseFQName(vs.Values[0])
+			}
+		}
+
+	case *ast.CallExpr:
+		if callName(t) != "BuildFQName" || len(t.Args) != 3 {
+			return "", ""
+		}
+		namespace, ok := v.parseValue("namespace", t.Args[0])
+		if !ok {
+			return "", ""
+		}
+		subsystem, ok := v.parseValue("subsystem", t.Args[1])
+		if !ok {
+			return "", ""
+		}
+		return namespace, subsystem
+	}
+
+	return "", ""
+}
+
 func (v *visitor) parseConstMetricOptsExpr(n ast.Node) *descCallExpr {
 	switch stmt := n.(type) {
 	case *ast.CallExpr:
 		return v.parseNewDescCallExpr(stmt)
 
+	case *ast.SelectorExpr:
+		// c.desc, assigned in the constructor of the collector.
+		if call := v.boundDesc(stmt.Sel.Name); call != nil {
+			return v.parseNewDescCallExpr(call)
+		}
+		if v.strict {
+			v.issues = append(v.issues, Issue{
+				Pos:    v.fs.Position(stmt.Pos()),
+				Metric: "",
+				Text:   fmt.Sprintf("parsing desc of type %T is not supported", stmt),
+			})
+		}
+
 	case *ast.Ident:
+		if stmt.Obj == nil {
+			// A variable declared in another file of the package.
+			if call := v.boundDesc(stmt.Name); call != nil {
+				return v.parseNewDescCallExpr(call)
+			}
+		}
 		if stmt.Obj != nil {
 			switch t := stmt.Obj.Decl.(type) {
 			case *ast.AssignStmt:
@@ -718,6 +1088,10 @@ type descCallExpr struct {
 	name, help  *string
 	labels      []string
 	constLabels [][2]string
+	// decl is the NewDesc call.
+	decl declaration
+
+	namespace, subsystem string
 }
 
 func (v *visitor) parseNewDescCallExpr(call *ast.CallExpr) *descCallExpr {
@@ -763,12 +1137,14 @@ func (v *visitor) parseNewDescCallExpr(call *ast.CallExpr) *descCallExpr {
 
 	// k8s.io/component-base/metrics.NewDesc has 6 args
 	// while prometheus.NewDesc has 4 args
-	if len(call.Args) < 4 && v.strict {
-		v.issues = append(v.issues, Issue{
-			Metric: "",
-			Pos:    v.fs.Position(call.Pos()),
-			Text:   "NewDesc should have at least 4 args",
-		})
+	if len(call.Args) < 4 {
+		if v.strict {
+			v.issues = append(v.issues, Issue{
+				Metric: "",
+				Pos:    v.fs.Position(call.Pos()),
+				Text:   "NewDesc should have at least 4 args",
+			})
+		}
 		return nil
 	}
 
@@ -785,7 +1161,9 @@ func (v *visitor) parseNewDescCallExpr(call *ast.CallExpr) *descCallExpr {
 	res := &descCallExpr{
 		name: &name,
 		help: &help,
+		decl: v.declarationOf(call),
 	}
+	res.namespace, res.subsystem = v.parseFQName(call.Args[0])
 
 	if x, ok := call.Args[2].(*ast.CompositeLit); ok {
 		opt := v.parseCompositeOpts(x)
@@ -802,14 +1180,34 @@ func (v *visitor) parseNewDescCallExpr(call *ast.CallExpr) *descCallExpr {
 			return nil
 		}
 
-		for k, v := range opt.constLabels {
-			res.constLabels = append(res.constLabels, [2]string{k, v})
-		}
+		res.constLabels = sortedConstLabels(opt.constLabels)
 	}
 
 	return res
 }
 
+// unquote returns the value of a string literal, or the literal itself if
+// it is not a string.
+func unquote(lit string) string {
+	if s, err := strconv.Unquote(lit); err == nil {
+		return s
+	}
+	return lit
+}
+
+// sortedConstLabels returns the const labels as name and value pairs
+// ordered by name.
+func sortedConstLabels(constLabels map[string]string) [][2]string {
+	labels := make([][2]string, 0, len(constLabels))
+	for k, v := range constLabels {
+		labels = append(labels, [2]string{k, v})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i][0] < labels[j][0]
+	})
+	return labels
+}
+
 func mustUnquote(str string) string {
 	stringLiteral, err := strconv.Unquote(str)
 	if err != nil {
@@ -18,8 +18,8 @@ func TestRun(t *testing.T) {
 
 	issues := RunLint(fs, []*ast.File{file}, Setting{Strict: false, DisabledLintFuncs: nil})
 
-	if len(issues) != 7 {
-		t.Fatalf("expect 7 issue, got %d, issues: %+#v", len(issues), issues)
+	if len(issues) != 9 {
+		t.Fatalf("expect 9 issue, got %d, issues: %+#v", len(issues), issues)
 	}
 
 	for idx, iss := range issues {
@@ -40,6 +40,12 @@ func TestRun(t *testing.T) {
 		case "test_histogram_duration_seconds":
 			assert.Equal(t, iss.Text, `metric name should not include type 'histogram'`)
 
+		case "prometheus_operator_spec_replicas":
+			assert.Contains(t, []string{
+				`registration will panic: "const-label1" is not a valid label name`,
+				`registration will panic: "const-label2" is not a valid label name`,
+			}, iss.Text)
+
 		default:
 			assert.Truef(t, false, "unexpected issue: %q", iss)
 		}
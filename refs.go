@@ -0,0 +1,367 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// binding is a variable or struct field a metric is assigned to.
+// Bindings are matched by name within the package directory, as files are
+// parsed without type information.
+type binding struct {
+	dir  string
+	name string
+}
+
+// metricCall is a method call or a type assertion on an expression which
+// may refer to a metric, e.g. `requests.WithLabelValues("GET").Inc()`.
+type metricCall struct {
+	// method is the called method, or the asserted interface for type assertions.
+	method string
+	recv   ast.Expr
+	args   []ast.Expr
+	dir    string
+	pos    token.Position
+}
+
+// childMethods return a child metric of a vector, or a curried vector.
+var childMethods = map[string]bool{
+	"WithLabelValues":          true,
+	"With":                     true,
+	"GetMetricWithLabelValues": true,
+	"GetMetricWith":            true,
+	"CurryWith":                true,
+	"MustCurryWith":            true,
+}
+
+// updateMethods change the value of a metric.
+var updateMethods = map[string]bool{
+	"Inc":                 true,
+	"Dec":                 true,
+	"Add":                 true,
+	"Sub":                 true,
+	"Set":                 true,
+	"SetToCurrentTime":    true,
+	"Observe":             true,
+	"ObserveWithExemplar": true,
+	"AddWithExemplar":     true,
+}
+
+// deleteMethods remove series from a vector.
+var deleteMethods = map[string]bool{
+	"Delete":             true,
+	"DeleteLabelValues":  true,
+	"DeletePartialMatch": true,
+	"Reset":              true,
+}
+
+// metricInterfaces are the interfaces recorded when a metric is asserted to them.
+var metricInterfaces = map[string]bool{
+	"ExemplarObserver": true,
+	"ExemplarAdder":    true,
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+func bindingName(n ast.Expr) string {
+	switch t := n.(type) {
+	case *ast.Ident:
+		if t.Name != "_" {
+			return t.Name
+		}
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// bind records that the value, if it is a call, is assigned to the name.
+func (v *visitor) bind(name ast.Expr, value ast.Expr) {
+	call, ok := value.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	if n := bindingName(name); n != "" {
+		v.bindings[call] = n
+	}
+}
+
+// collectDescs records the NewDesc calls assigned to variables or struct
+// fields, so a desc sent by a collector can be found wherever it is created,
+// e.g. in the constructor of the collector.
+func (v *visitor) collectDescs(file *ast.File, dir string) {
+	record := func(name, value ast.Expr) {
+		call, ok := value.(*ast.CallExpr)
+		if !ok || callName(call) != "NewDesc" {
+			return
+		}
+		if n := bindingName(name); n != "" {
+			b := binding{dir: dir, name: n}
+			v.descs[b] = append(v.descs[b], call)
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.AssignStmt:
+			if len(t.Lhs) == len(t.Rhs) {
+				for i := range t.Lhs {
+					record(t.Lhs[i], t.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			for i := range t.Names {
+				if i < len(t.Values) {
+					record(t.Names[i], t.Values[i])
+				}
+			}
+		case *ast.KeyValueExpr:
+			record(t.Key, t.Value)
+		}
+		return true
+	})
+}
+
+// boundDesc returns the NewDesc call assigned to the name in the directory
+// being walked, if there is exactly one.
+func (v *visitor) boundDesc(name string) *ast.CallExpr {
+	if calls := v.descs[binding{dir: v.dir, name: name}]; len(calls) == 1 {
+		return calls[0]
+	}
+	return nil
+}
+
+func (v *visitor) parseMetricCall(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || v.chained[call] {
+		return
+	}
+
+	method, recv, args := sel.Sel.Name, sel.X, call.Args
+	switch {
+	case updateMethods[method], deleteMethods[method], childMethods[method], method == "Collect":
+
+	// The metric passed to a timer or an instrumented handler is updated by them, e.g.
+	//
+	//	prometheus.NewTimer(duration.WithLabelValues("GET"))
+	//	promhttp.InstrumentHandlerCounter(requests, handler)
+	case method == "NewTimer", strings.HasPrefix(method, "Instrument"):
+		if len(call.Args) == 0 {
+			return
+		}
+		recv, args = call.Args[0], call.Args[1:]
+
+	default:
+		return
+	}
+
+	// `vec.WithLabelValues("a").Inc()` is a single site, so the child
+	// lookup isn't recorded on its own.
+	if child := childCall(recv); child != nil {
+		v.chained[child] = true
+	}
+
+	v.calls = append(v.calls, metricCall{
+		method: method,
+		recv:   recv,
+		args:   args,
+		dir:    v.dir,
+		pos:    v.fs.Position(call.Pos()),
+	})
+}
+
+// childCall returns the child lookup the expression is made of, if any.
+func childCall(n ast.Expr) *ast.CallExpr {
+	switch t := n.(type) {
+	case *ast.ParenExpr:
+		return childCall(t.X)
+	case *ast.TypeAssertExpr:
+		return childCall(t.X)
+	case *ast.CallExpr:
+		if sel, ok := t.Fun.(*ast.SelectorExpr); ok && childMethods[sel.Sel.Name] {
+			return t
+		}
+	}
+	return nil
+}
+
+func (v *visitor) parseTypeAssertExpr(expr *ast.TypeAssertExpr) {
+	var name string
+	switch t := expr.Type.(type) {
+	case *ast.Ident:
+		name = t.Name
+	case *ast.SelectorExpr:
+		name = t.Sel.Name
+	}
+
+	if !metricInterfaces[name] {
+		return
+	}
+
+	v.calls = append(v.calls, metricCall{
+		method: name,
+		recv:   expr.X,
+		dir:    v.dir,
+		pos:    v.fs.Position(expr.Pos()),
+	})
+}
+
+// ref is the variable or field an expression refers to.
+type ref struct {
+	name string
+	// qualified is set for fields and package members, which may be bound
+	// in another package.
+	qualified bool
+	// asserted is set if a type assertion was passed on the way.
+	asserted bool
+}
+
+// refOf unwraps the expression to the variable or field holding the metric.
+// Child lookups like `WithLabelValues`, parentheses and local aliases are
+// followed.
+func refOf(n ast.Expr) ref {
+	return refOfDepth(n, 0)
+}
+
+func refOfDepth(n ast.Expr, depth int) ref {
+	// Guard against cycles like `x = x.WithLabelValues("a")`.
+	if depth > 8 {
+		return ref{}
+	}
+
+	switch t := n.(type) {
+	case *ast.ParenExpr:
+		return refOfDepth(t.X, depth+1)
+
+	case *ast.TypeAssertExpr:
+		r := refOfDepth(t.X, depth+1)
+		r.asserted = true
+		return r
+
+	case *ast.CallExpr:
+		if sel, ok := t.Fun.(*ast.SelectorExpr); ok && childMethods[sel.Sel.Name] {
+			return refOfDepth(sel.X, depth+1)
+		}
+
+	case *ast.SelectorExpr:
+		return ref{name: t.Sel.Name, qualified: true}
+
+	case *ast.Ident:
+		if rhs := aliasOf(t); rhs != nil {
+			if r := refOfDepth(rhs, depth+1); r.name != "" {
+				return r
+			}
+		}
+		return ref{name: t.Name}
+	}
+
+	return ref{}
+}
+
+// aliasOf returns the expression a local identifier was assigned from,
+// e.g. `h.WithLabelValues("a")` for `obs := h.WithLabelValues("a")`.
+func aliasOf(ident *ast.Ident) ast.Expr {
+	if ident.Obj == nil {
+		return nil
+	}
+
+	var (
+		lhs []ast.Expr
+		rhs []ast.Expr
+	)
+	switch decl := ident.Obj.Decl.(type) {
+	case *ast.AssignStmt:
+		lhs, rhs = decl.Lhs, decl.Rhs
+	case *ast.ValueSpec:
+		for _, name := range decl.Names {
+			lhs = append(lhs, name)
+		}
+		rhs = decl.Values
+	default:
+		return nil
+	}
+
+	// `m, err := h.GetMetricWithLabelValues("a")`
+	if len(rhs) == 1 && len(lhs) > 1 {
+		return rhs[0]
+	}
+
+	for i := range lhs {
+		if id, ok := lhs[i].(*ast.Ident); ok && id.Name == ident.Name && i < len(rhs) {
+			return rhs[i]
+		}
+	}
+
+	return nil
+}
+
+// lookupMetric finds the metric bound to the reference. Bindings in the
+// same package directory are preferred, other packages are only searched
+// for qualified references.
+func (v *visitor) lookupMetric(dir string, r ref) *MetricFamilyWithPos {
+	if r.name == "" {
+		return nil
+	}
+
+	if i, ok := v.byBinding[binding{dir: dir, name: r.name}]; ok {
+		return &v.metrics[i]
+	}
+	if i, ok := v.byBindingName[r.name]; ok && r.qualified {
+		return &v.metrics[i]
+	}
+	return nil
+}
+
+// resolve returns the metric the call is made on, or nil if it is unknown.
+func (v *visitor) resolve(c metricCall) (*MetricFamilyWithPos, bool) {
+	r := refOf(c.recv)
+	return v.lookupMetric(c.dir, r), r.asserted
+}
+
+// resolveCalls links the metrics to the sites updating them or deleting
+// their series.
+func (v *visitor) resolveCalls() {
+	for _, c := range v.calls {
+		m, _ := v.resolve(c)
+		if m == nil {
+			continue
+		}
+
+		switch {
+		case updateMethods[c.method], childMethods[c.method],
+			c.method == "NewTimer", strings.HasPrefix(c.method, "Instrument"):
+			m.Updates = append(m.Updates, c.pos)
+		case deleteMethods[c.method]:
+			m.Deletes = append(m.Deletes, c.pos)
+		case c.method == "Collect":
+			m.collected = true
+		}
+	}
+}
+
+// lintUnused reports metrics which are never updated and always export
+// their zero value. Func and const metrics get their value when collected.
+func (v *visitor) lintUnused() {
+	for _, m := range v.metrics {
+		if _, ok := constMetricArgNum[m.constructor]; ok || m.constructor == "NewFamilyGenerator" ||
+			strings.HasSuffix(m.constructor, "Func") || len(m.Updates) > 0 {
+			continue
+		}
+
+		v.issues = append(v.issues, Issue{
+			Pos:    m.Pos,
+			Metric: m.MetricFamily.GetName(),
+			Text:   "metric is never updated",
+		})
+	}
+}
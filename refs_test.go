@@ -0,0 +1,84 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnused(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "unused.go", `package foo
+
+var (
+	duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "request_duration_seconds",
+		Help: "Request duration.",
+	}, []string{"method"})
+
+	inflight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "inflight_requests",
+		Help: "In-flight requests.",
+	})
+
+	errors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "errors_total",
+		Help: "Errors.",
+	})
+
+	goroutines = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "goroutines",
+		Help: "Goroutines.",
+	}, func() float64 { return 1 })
+)
+
+func handle(h http.Handler) http.Handler {
+	timer := prometheus.NewTimer(duration.WithLabelValues("GET"))
+	defer timer.ObserveDuration()
+
+	return promhttp.InstrumentHandlerInFlight(inflight, h)
+}
+`)
+
+	metrics := RunList(fs, []*ast.File{file}, false)
+	updates := map[string]int{}
+	for _, m := range metrics {
+		updates[m.MetricFamily.GetName()] = len(m.Updates)
+	}
+	assert.Equal(t, map[string]int{
+		"request_duration_seconds": 1,
+		"inflight_requests":        1,
+		"errors_total":             0,
+		"goroutines":               0,
+	}, updates)
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{EnabledLintFuncs: []string{"Unused"}})
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "errors_total", issues[0].Metric)
+		assert.Equal(t, "metric is never updated", issues[0].Text)
+	}
+}
+
+func TestBoundDescOtherPackage(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "collector.go", `package foo
+
+type collector struct {
+	desc *prometheus.Desc
+}
+
+func newCollector() *collector {
+	return &collector{desc: mylib.NewDesc("foo_items", "Items.")}
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+}
+`)
+
+	// The NewDesc of another package, with 2 args, isn't a metric.
+	assert.Empty(t, RunLint(fs, []*ast.File{file}, Setting{}))
+	assert.Contains(t, issueTexts(RunLint(fs, []*ast.File{file}, Setting{Strict: true})), ": NewDesc should have at least 4 args")
+}
@@ -0,0 +1,358 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strings"
+)
+
+const (
+	prometheusPath = "github.com/prometheus/client_golang/prometheus"
+	promautoPath   = "github.com/prometheus/client_golang/prometheus/promauto"
+
+	// defaultRegisterer is the registerer used by the package level
+	// functions of client_golang and promauto.
+	defaultRegisterer = "prometheus.DefaultRegisterer"
+	// pushRegisterer is used for collectors pushed directly to a Pushgateway.
+	pushRegisterer = "push.Pusher"
+	// freshRegistry is a registry created inline, e.g.
+	// promauto.With(prometheus.NewRegistry()), which can't be exposed.
+	freshRegistry = "prometheus.NewRegistry()"
+)
+
+// registration is a metric created by promauto, or collectors passed to
+// Register or MustRegister.
+type registration struct {
+	// registerer is the name the registerer is bound to, or defaultRegisterer.
+	registerer string
+
+	// promauto is set for metrics created by a promauto constructor.
+	promauto bool
+	metric   string
+
+	// fn and args are the called function, Register or MustRegister,
+	// and the collectors passed to it.
+	fn   string
+	args []ast.Expr
+
+	pkg string
+	dir string
+	pos token.Position
+}
+
+// registry is a registry created by prometheus.NewRegistry.
+type registry struct {
+	name string
+	dir  string
+	pos  token.Position
+}
+
+// exposers are the functions exposing the registry passed as first argument.
+var exposers = map[string]bool{
+	"HandlerFor":              true,
+	"HandlerForTransactional": true,
+	"Gatherer":                true,
+}
+
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		p := mustUnquote(spec.Path.Value)
+		name := path.Base(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = p
+	}
+	return imports
+}
+
+// isPackage reports whether the expression is the name of the imported
+// package. Without the import, the default package name is assumed.
+func (v *visitor) isPackage(n ast.Expr, pkgPath string) bool {
+	ident, ok := n.(*ast.Ident)
+	if !ok || ident.Obj != nil {
+		return false
+	}
+
+	if p, ok := v.imports[ident.Name]; ok {
+		return p == pkgPath
+	}
+	return ident.Name == path.Base(pkgPath)
+}
+
+// registererName returns the name of the registerer, or an empty string if
+// the expression isn't one.
+func (v *visitor) registererName(n ast.Expr) string {
+	switch t := n.(type) {
+	case *ast.SelectorExpr:
+		if v.isPackage(t.X, prometheusPath) {
+			if t.Sel.Name == "DefaultRegisterer" {
+				return defaultRegisterer
+			}
+			return ""
+		}
+		return t.Sel.Name
+
+	case *ast.Ident:
+		if t.Name == "nil" || t.Name == "_" {
+			return ""
+		}
+		if _, ok := v.imports[t.Name]; ok && t.Obj == nil {
+			return ""
+		}
+		// A registry bound to the name is known by the name.
+		if rhs := aliasOf(t); rhs != nil {
+			if name := v.registererName(rhs); name != "" && name != freshRegistry {
+				return name
+			}
+		}
+		return t.Name
+
+	case *ast.CallExpr:
+		sel, ok := t.Fun.(*ast.SelectorExpr)
+		if !ok || !v.isPackage(sel.X, prometheusPath) {
+			return ""
+		}
+		// prometheus.WrapRegistererWith(labels, reg)
+		if strings.HasPrefix(sel.Sel.Name, "WrapRegistererWith") && len(t.Args) == 2 {
+			return v.registererName(t.Args[1])
+		}
+		// prometheus.NewRegistry()
+		if sel.Sel.Name == "NewRegistry" || sel.Sel.Name == "NewPedanticRegistry" {
+			return freshRegistry
+		}
+	}
+
+	return ""
+}
+
+// promautoRegisterer returns the registerer of a metric created by promauto,
+// or an empty string if the call isn't a promauto constructor.
+func (v *visitor) promautoRegisterer(call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+
+	switch x := sel.X.(type) {
+	// promauto.NewCounter(...)
+	case *ast.Ident:
+		if v.isPackage(x, promautoPath) {
+			return defaultRegisterer
+		}
+
+		// factory := promauto.With(reg)
+		// factory.NewCounter(...)
+		if rhs, ok := aliasOf(x).(*ast.CallExpr); ok {
+			return v.promautoWithRegisterer(rhs)
+		}
+
+	// promauto.With(reg).NewCounter(...)
+	case *ast.CallExpr:
+		return v.promautoWithRegisterer(x)
+	}
+
+	return ""
+}
+
+func (v *visitor) promautoWithRegisterer(call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "With" || len(call.Args) != 1 || !v.isPackage(sel.X, promautoPath) {
+		return ""
+	}
+	return v.registererName(call.Args[0])
+}
+
+func (v *visitor) parseRegisterCall(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	switch sel.Sel.Name {
+	case "NewRegistry", "NewPedanticRegistry":
+		if name, ok := v.bindings[call]; ok && v.isPackage(sel.X, prometheusPath) {
+			v.registries = append(v.registries, registry{name: name, dir: v.dir, pos: v.fs.Position(call.Pos())})
+		}
+		return
+
+	case "MustRegister", "Register":
+
+	// push.New(url, job).Collector(c) pushes the collector without a registry.
+	case "Collector":
+		v.registrations = append(v.registrations, registration{
+			registerer: pushRegisterer,
+			fn:         sel.Sel.Name,
+			args:       call.Args,
+			pkg:        v.pkg,
+			dir:        v.dir,
+			pos:        v.fs.Position(call.Pos()),
+		})
+		return
+
+	default:
+		if exposers[sel.Sel.Name] && len(call.Args) > 0 {
+			if name := v.registererName(call.Args[0]); name != "" {
+				v.exposures = append(v.exposures, binding{dir: v.dir, name: name})
+			}
+		}
+		return
+	}
+
+	registerer := defaultRegisterer
+	if !v.isPackage(sel.X, prometheusPath) {
+		// Registries of other libraries, e.g. legacyregistry.MustRegister(c).
+		if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil && v.imports[id.Name] != "" {
+			registerer = v.imports[id.Name]
+		} else if registerer = v.registererName(sel.X); registerer == "" {
+			return
+		}
+	}
+
+	// prometheus.MustRegister(prometheus.NewCounter(...))
+	for _, arg := range call.Args {
+		if c, ok := arg.(*ast.CallExpr); ok {
+			v.registeredCalls[c] = registerer
+		}
+	}
+
+	v.registrations = append(v.registrations, registration{
+		registerer: registerer,
+		fn:         sel.Sel.Name,
+		args:       call.Args,
+		pkg:        v.pkg,
+		dir:        v.dir,
+		pos:        v.fs.Position(call.Pos()),
+	})
+}
+
+// lintGlobalRegisterer reports metrics registered on the global registerer
+// by library packages, which pollutes every binary importing them.
+func (v *visitor) lintGlobalRegisterer(l LibrarySetting) {
+	for _, r := range v.registrations {
+		if r.registerer != defaultRegisterer || !l.isLibrary(r.pkg, r.dir) {
+			continue
+		}
+
+		if r.promauto {
+			v.issues = append(v.issues, Issue{
+				Pos:    r.pos,
+				Metric: r.metric,
+				Text: fmt.Sprintf("library package %s should not register metrics on the global registerer via promauto, "+
+					"accept a prometheus.Registerer and use promauto.With instead", r.pkg),
+			})
+			continue
+		}
+
+		for _, arg := range r.args {
+			metric := ""
+			if m := v.lookupMetric(r.dir, refOf(arg)); m != nil {
+				metric = m.MetricFamily.GetName()
+			}
+
+			v.issues = append(v.issues, Issue{
+				Pos:    v.fs.Position(arg.Pos()),
+				Metric: metric,
+				Text: fmt.Sprintf("library package %s should not register metrics on the global registerer via prometheus.%s, "+
+					"accept a prometheus.Registerer instead", r.pkg, r.fn),
+			})
+		}
+	}
+}
+
+// exposed reports whether the registerer is exposed. Only the registries
+// created by prometheus.NewRegistry are known not to be, unless they are
+// passed to promhttp.HandlerFor or a push client.
+func (v *visitor) exposed(r binding) bool {
+	if r.name == freshRegistry {
+		return false
+	}
+
+	known := false
+	for _, reg := range v.registries {
+		if reg.name == r.name && reg.dir == r.dir {
+			known = true
+		}
+	}
+	if !known {
+		return true
+	}
+
+	for _, e := range v.exposures {
+		if e.name == r.name && e.dir == r.dir {
+			return true
+		}
+	}
+	return false
+}
+
+// lintUnregistered reports the metrics which never show up on /metrics,
+// as they are never registered or only registered on a registry which is
+// never exposed.
+func (v *visitor) lintUnregistered() {
+	for _, r := range v.registrations {
+		if r.promauto {
+			continue
+		}
+		for _, arg := range r.args {
+			if m := v.lookupMetric(r.dir, refOf(arg)); m != nil {
+				m.registerers = append(m.registerers, binding{dir: r.dir, name: r.registerer})
+			}
+		}
+	}
+
+	for _, m := range v.metrics {
+		// Const metrics and the ones forwarded by a collector are exposed
+		// with the collector.
+		if _, ok := constMetricArgNum[m.constructor]; ok || m.constructor == "NewFamilyGenerator" || m.collected {
+			continue
+		}
+
+		if len(m.registerers) == 0 {
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: m.MetricFamily.GetName(),
+				Text:   "metric is never registered, register it or create it with promauto",
+			})
+			continue
+		}
+
+		var unexposed []string
+		fresh := true
+		for _, r := range m.registerers {
+			if v.exposed(r) {
+				unexposed = nil
+				break
+			}
+			unexposed = append(unexposed, fmt.Sprintf("%q", r.name))
+			fresh = fresh && r.name == freshRegistry
+		}
+		if len(unexposed) > 0 && fresh {
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: m.MetricFamily.GetName(),
+				Text:   "metric is only registered on a fresh registry created inline by prometheus.NewRegistry, which is never exposed",
+			})
+		} else if len(unexposed) > 0 {
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: m.MetricFamily.GetName(),
+				Text: fmt.Sprintf("metric is only registered on registry %s which is never exposed",
+					strings.Join(unexposed, ", ")),
+			})
+		}
+	}
+
+	for _, reg := range v.registries {
+		if !v.exposed(binding{dir: reg.dir, name: reg.name}) {
+			v.issues = append(v.issues, Issue{
+				Pos:    reg.pos,
+				Metric: "",
+				Text:   fmt.Sprintf("registry %q is never exposed via promhttp.HandlerFor or a push client", reg.name),
+			})
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobalRegisterer(t *testing.T) {
+	fs := token.NewFileSet()
+	lib := parseSource(t, fs, "pkg/store/metrics.go", `package store
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var hits = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "cache_hits_total",
+	Help: "Cache hits.",
+})
+
+var misses = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "cache_misses_total",
+	Help: "Cache misses.",
+})
+
+func init() {
+	prometheus.MustRegister(misses)
+}
+
+func NewMetrics(reg prometheus.Registerer) {
+	_ = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "cache_evictions_total",
+		Help: "Cache evictions.",
+	})
+	reg.MustRegister(misses)
+}
+`)
+	bin := parseSource(t, fs, "cmd/store/main.go", `package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var up = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "store_up",
+	Help: "Whether the store is up.",
+})
+`)
+	files := []*ast.File{lib, bin}
+
+	issues := RunLint(fs, files, Setting{})
+	assert.Empty(t, issues)
+
+	issues = RunLint(fs, files, Setting{EnabledLintFuncs: []string{"GlobalRegisterer"}})
+	if assert.Len(t, issues, 2) {
+		assert.Equal(t, "cache_hits_total", issues[0].Metric)
+		assert.Equal(t, "library package store should not register metrics on the global registerer via promauto, "+
+			"accept a prometheus.Registerer and use promauto.With instead", issues[0].Text)
+		assert.Equal(t, "cache_misses_total", issues[1].Metric)
+		assert.Equal(t, "library package store should not register metrics on the global registerer via prometheus.MustRegister, "+
+			"accept a prometheus.Registerer instead", issues[1].Text)
+	}
+
+	issues = RunLint(fs, files, Setting{
+		EnabledLintFuncs: []string{"GlobalRegisterer"},
+		Libraries:        LibrarySetting{Paths: []string{"cmd/..."}},
+	})
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "store_up", issues[0].Metric)
+	}
+}
+
+func TestUnregistered(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "main.go", `package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type collector struct {
+	queue prometheus.Gauge
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- c.queue
+}
+
+func main() {
+	reg := prometheus.NewRegistry()
+	unused := prometheus.NewRegistry()
+
+	requests := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."})
+	reg.MustRegister(requests)
+
+	errors := prometheus.NewCounter(prometheus.CounterOpts{Name: "errors_total", Help: "Errors."})
+	unused.MustRegister(errors)
+
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "dropped_total", Help: "Dropped."})
+	_ = promauto.With(nil).NewCounter(prometheus.CounterOpts{Name: "retries_total", Help: "Retries."})
+	_ = promauto.With(reg).NewCounter(prometheus.CounterOpts{Name: "panics_total", Help: "Panics."})
+	_ = promauto.With(prometheus.NewRegistry()).NewCounter(prometheus.CounterOpts{Name: "timeouts_total", Help: "Timeouts."})
+	prometheus.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "up", Help: "Up."}))
+
+	_ = &collector{
+		queue: prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_length", Help: "Queue length."}),
+	}
+
+	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+}
+`)
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{EnabledLintFuncs: []string{"Unregistered"}})
+	texts := issueTexts(issues)
+	assert.ElementsMatch(t, []string{
+		`errors_total: metric is only registered on registry "unused" which is never exposed`,
+		"dropped_total: metric is never registered, register it or create it with promauto",
+		"retries_total: metric is never registered, register it or create it with promauto",
+		"timeouts_total: metric is only registered on a fresh registry created inline by prometheus.NewRegistry, which is never exposed",
+		`: registry "unused" is never exposed via promhttp.HandlerFor or a push client`,
+	}, texts)
+}
@@ -0,0 +1,269 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Severity is the severity of the issues reported by a rule.
+type Severity string
+
+// The severities, from the most to the least severe.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// ParseSeverity returns the severity named s.
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(s); sev {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return sev, nil
+	}
+	return "", fmt.Errorf("unknown severity %q, must be %q, %q or %q",
+		s, SeverityError, SeverityWarning, SeverityInfo)
+}
+
+// docsURL is the documentation of the rules, with an anchor for each rule.
+const docsURL = "https://github.com/yeya24/promlinter/blob/master/docs/rules.md#"
+
+// parsingRule reports the parsing failures in strict mode.
+const parsingRule = "Parsing"
+
+// Rule is a lint rule. Its ID is stable and used to disable, enable and
+// configure the rule.
+type Rule struct {
+	ID          string
+	Description string
+	// Severity is the default severity of the issues reported by the rule.
+	Severity Severity
+	// Docs is the link to the documentation of the rule.
+	Docs string
+	// Optional rules only run when enabled.
+	Optional bool
+
+	lint func(v *visitor, s Setting)
+}
+
+var (
+	// Rules are all the rules, in the order they run.
+	Rules []Rule
+	// RuleAliases map the former names of rules to their ID.
+	RuleAliases = map[string]string{
+		"lintUnitAbbreviations": "UnitAbbreviations",
+	}
+
+	rulesByID = map[string]*Rule{}
+)
+
+// RuleByID returns the rule with the ID or alias.
+func RuleByID(id string) (Rule, bool) {
+	r, ok := rulesByID[ruleID(id)]
+	if !ok {
+		return Rule{}, false
+	}
+	return *r, true
+}
+
+// ruleID returns the ID of the rule named by the ID or an alias.
+func ruleID(name string) string {
+	if id, ok := RuleAliases[name]; ok {
+		return id
+	}
+	return name
+}
+
+// severity returns the severity of the issues reported by the rule, the
+// one set in the setting or the default one.
+func (s Setting) severity(r *Rule) Severity {
+	if sev, ok := s.Severities[r.ID]; ok {
+		return sev
+	}
+	for name, sev := range s.Severities {
+		if ruleID(name) == r.ID {
+			return sev
+		}
+	}
+	return r.Severity
+}
+
+// families returns the lint function of a rule checking each metric family
+// on its own.
+func families(fn func(mf *dto.MetricFamily) []string) func(*visitor, Setting) {
+	return func(v *visitor, _ Setting) {
+		for i := range v.metrics {
+			v.appendIssues(&v.metrics[i], fn(v.metrics[i].MetricFamily))
+		}
+	}
+}
+
+func init() {
+	rule := func(id string, severity Severity, description string, lint func(*visitor, Setting)) Rule {
+		return Rule{
+			ID:          id,
+			Description: description,
+			Severity:    severity,
+			Docs:        docsURL + strings.ToLower(id),
+			lint:        lint,
+		}
+	}
+	optional := func(id string, severity Severity, description string, lint func(*visitor, Setting)) Rule {
+		r := rule(id, severity, description, lint)
+		r.Optional = true
+		return r
+	}
+
+	Rules = []Rule{
+		rule(parsingRule, SeverityWarning,
+			"Parsing detects metric options which can't be parsed statically. It only runs with flag --strict.",
+			// The parsing failures are reported while walking the files.
+			func(*visitor, Setting) {}),
+		rule(suppressionRule, SeverityWarning,
+			`Suppression detects //promlinter:ignore comments which are unused, name unknown rules or have no reason, e.g. //promlinter:ignore Counter reason="legacy dashboards".`,
+			// The suppressions are checked once applied to the issues.
+			func(*visitor, Setting) {}),
+
+		// The rules of promlint.
+		rule("Help", SeverityError,
+			"Help detects issues related to the help text for a metric.",
+			families(lintHelp)),
+		rule("MetricUnits", SeverityError,
+			"MetricUnits detects issues with metric unit names.",
+			families(lintMetricUnits)),
+		rule("Counter", SeverityError,
+			"Counter detects issues specific to counters, as well as patterns that should only be used with counters.",
+			families(lintCounter)),
+		rule("HistogramSummaryReserved", SeverityError,
+			"HistogramSummaryReserved detects when other types of metrics use names or labels reserved for use by histograms and/or summaries.",
+			families(lintHistogramSummaryReserved)),
+		rule("MetricTypeInName", SeverityError,
+			"MetricTypeInName detects when metric types are included in the metric name.",
+			families(lintMetricTypeInName)),
+		rule("ReservedChars", SeverityError,
+			"ReservedChars detects colons in metric names.",
+			families(lintReservedChars)),
+		rule("CamelCase", SeverityError,
+			"CamelCase detects metric names and label names written in camelCase.",
+			families(lintCamelCase)),
+		rule("UnitAbbreviations", SeverityError,
+			"UnitAbbreviations detects abbreviated units in the metric name.",
+			families(lintUnitAbbreviations)),
+
+		rule("Exemplar", SeverityError,
+			"Exemplar detects invalid or oversized exemplar labels and exemplars attached to metric types which don't support them.",
+			func(v *visitor, _ Setting) { v.lintExemplars() }),
+		rule("WillPanic", SeverityError,
+			"WillPanic detects metrics which make client_golang panic when created or registered, e.g. invalid names, duplicate labels or unsorted buckets.",
+			func(v *visitor, _ Setting) { v.lintWillPanic() }),
+		rule("OpenMetrics", SeverityError,
+			"OpenMetrics detects metrics breaking the OpenMetrics conventions, e.g. counters without the _total suffix if the Counter rule is disabled, reserved suffixes, "+
+				"units which are not base units or not the suffix of the name, info metrics or statesets with a unit, "+
+				"gauge histograms with the _total suffix, and statesets with a le label or without a label named after the metric. "+
+				"It only runs with flag --openmetrics.",
+			func(v *visitor, s Setting) {
+				if s.OpenMetrics {
+					v.lintOpenMetrics(!s.isDisabled("Counter"))
+				}
+			}),
+		rule("Escaping", SeverityError,
+			"Escaping detects metric names, and label names of a metric, which collide once escaped with the underscores, dots or values scheme. "+
+				"It only runs with flag --name-validation=utf8.",
+			func(v *visitor, _ Setting) {
+				if v.utf8Names {
+					v.lintEscaping()
+				}
+			}),
+
+		optional("GlobalRegisterer", SeverityWarning,
+			"GlobalRegisterer detects library packages registering metrics on the global prometheus.DefaultRegisterer.",
+			func(v *visitor, s Setting) { v.lintGlobalRegisterer(s.Libraries) }),
+		optional("StaleSeries", SeverityWarning,
+			"StaleSeries detects vectors with labels identifying transient entities whose series are never deleted.",
+			func(v *visitor, s Setting) { v.lintStaleSeries(s.EntityLabels) }),
+		optional("Unregistered", SeverityWarning,
+			"Unregistered detects metrics which are never registered, and registries which are never exposed via promhttp.HandlerFor or a push client.",
+			func(v *visitor, _ Setting) { v.lintUnregistered() }),
+		optional("Unused", SeverityWarning,
+			"Unused detects metrics which are never updated, except Func and const metrics.",
+			func(v *visitor, _ Setting) { v.lintUnused() }),
+		optional("Conflict", SeverityError,
+			"Conflict detects metrics declared several times with a different type, label set or help text.",
+			func(v *visitor, _ Setting) { v.lintConflicts() }),
+		optional("NearDuplicate", SeverityWarning,
+			"NearDuplicate detects metric names which are likely accidental duplicates, e.g. differing by plural, a synonym, a typo or a token inserted or dropped.",
+			func(v *visitor, s Setting) { v.lintNearDuplicates(s.Synonyms) }),
+		optional("LabelVocabulary", SeverityWarning,
+			"LabelVocabulary detects label names which are forbidden synonyms in the vocabulary, or written in different forms across metrics.",
+			func(v *visitor, s Setting) { v.lintLabelVocabulary(s.LabelVocabulary) }),
+		optional("Namespace", SeverityWarning,
+			"Namespace detects metrics breaking the namespace policy of their package, by default the metrics of a directory must share a namespace.",
+			func(v *visitor, s Setting) { v.lintNamespaces(s.Namespaces) }),
+
+		optional("HelpCapitalized", SeverityWarning,
+			"HelpCapitalized detects help texts not starting with a capital letter.",
+			helpTexts(lintHelpCapitalized)),
+		optional("HelpPeriod", SeverityWarning,
+			"HelpPeriod detects help texts not ending like the others, with or without a period.",
+			func(v *visitor, s Setting) { v.lintHelpPeriod(s.Help.Period) }),
+		optional("HelpMinLength", SeverityWarning,
+			"HelpMinLength detects help texts shorter than the minimum length.",
+			func(v *visitor, s Setting) { v.lintHelpMinLength(s.Help.MinLength) }),
+		optional("HelpRepeatsName", SeverityWarning,
+			"HelpRepeatsName detects help texts only repeating the metric name.",
+			helpTexts(lintHelpRepeatsName)),
+		optional("HelpPlaceholder", SeverityWarning,
+			"HelpPlaceholder detects help texts containing placeholders like TODO or FIXME.",
+			helpTexts(lintHelpPlaceholder)),
+		optional("HelpEmpty", SeverityWarning,
+			"HelpEmpty detects empty help texts.",
+			func(v *visitor, _ Setting) { v.lintHelpEmpty() }),
+		optional("HelpDuplicate", SeverityWarning,
+			"HelpDuplicate detects distinct metrics with identical help texts.",
+			func(v *visitor, _ Setting) { v.lintHelpDuplicates() }),
+		optional("HelpUnit", SeverityWarning,
+			"HelpUnit detects help texts mentioning a unit which contradicts the unit suffix of the metric name, e.g. milliseconds for *_seconds.",
+			func(v *visitor, _ Setting) { v.lintHelpUnits() }),
+
+		optional("UnitPolicy", SeverityWarning,
+			"UnitPolicy detects metric names whose unit suffix breaks the unit policy, and ratios set outside of 0-1.",
+			func(v *visitor, s Setting) { v.lintUnitPolicy(s.Units) }),
+		optional("BestPractices", SeverityWarning,
+			"BestPractices detects names breaking the naming best practices, e.g. *_per_second gauges, *_info metrics which are not gauges or OpenMetrics infos set to 1, "+
+				"*_timestamp_seconds metrics which are not gauges, *_created metrics, label-like segments like *_for_user_x and trailing numbers.",
+			func(v *visitor, _ Setting) { v.lintBestPractices() }),
+
+		optional("LabelReserved", SeverityWarning,
+			`LabelReserved detects label names starting with "__", which is reserved for internal use.`,
+			labelNames(lintLabelReserved)),
+		optional("LabelCharset", SeverityWarning,
+			"LabelCharset detects label names with characters invalid under the legacy name scheme.",
+			labelNames(lintLabelCharset)),
+		optional("LabelTypeCollision", SeverityWarning,
+			"LabelTypeCollision detects le labels on metrics other than histograms, and quantile labels on metrics other than summaries.",
+			labelNames(lintLabelTypeCollision)),
+		optional("LabelCount", SeverityWarning,
+			"LabelCount detects metrics with more labels than the maximum.",
+			func(v *visitor, s Setting) { v.lintLabelCount(s.Labels.Max) }),
+		optional("LabelDeny", SeverityWarning,
+			"LabelDeny detects denied label names, e.g. with unbounded or sensitive values.",
+			func(v *visitor, s Setting) { v.lintLabelDeny(s.Labels.Deny) }),
+		optional("LabelRequired", SeverityWarning,
+			"LabelRequired detects metrics missing the labels required by their package.",
+			func(v *visitor, s Setting) { v.lintLabelRequired(s.Labels.Required) }),
+		optional("EscapedNames", SeverityInfo,
+			"EscapedNames detects metric and label names which are not valid legacy names, and so are escaped differently by each escaping scheme.",
+			func(v *visitor, _ Setting) { v.lintEscapedNames() }),
+	}
+
+	for i, r := range Rules {
+		rulesByID[r.ID] = &Rules[i]
+		if r.Optional {
+			OptionalLintFuncNames = append(OptionalLintFuncNames, r.ID)
+		} else {
+			LintFuncNames = append(LintFuncNames, r.ID)
+		}
+	}
+}
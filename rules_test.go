@@ -0,0 +1,133 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	docs, err := os.ReadFile("docs/rules.md")
+	require.NoError(t, err)
+
+	seen := map[string]bool{}
+	for _, r := range Rules {
+		assert.False(t, seen[r.ID], "duplicate rule %s", r.ID)
+		seen[r.ID] = true
+
+		assert.Contains(t, []Severity{SeverityError, SeverityWarning, SeverityInfo}, r.Severity, r.ID)
+		assert.True(t, strings.HasPrefix(r.Description, r.ID+" "), r.ID)
+		assert.Contains(t, string(docs), "\n## "+r.ID+"\n", r.ID)
+	}
+
+	r, ok := RuleByID("lintUnitAbbreviations")
+	assert.True(t, ok)
+	assert.Equal(t, "UnitAbbreviations", r.ID)
+	_, ok = RuleByID("Unknown")
+	assert.False(t, ok)
+}
+
+// TestPromlint checks that the reimplemented promlint rules report the
+// same problems as promlint.
+func TestPromlint(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "./testdata/testdata.go", nil, parser.AllErrors)
+	require.NoError(t, err)
+	files := []*ast.File{file}
+
+	var want []string
+	for _, m := range RunList(fs, files, false) {
+		problems, err := promlint.NewWithMetricFamilies([]*dto.MetricFamily{m.MetricFamily}).Lint()
+		require.NoError(t, err)
+		for _, p := range problems {
+			want = append(want, p.Metric+": "+p.Text)
+		}
+	}
+
+	var got []string
+	for _, iss := range RunLint(fs, files, Setting{DisabledLintFuncs: []string{"Exemplar", "WillPanic"}}) {
+		got = append(got, iss.Metric+": "+iss.Text)
+		assert.NotEmpty(t, iss.RuleID)
+	}
+
+	sort.Strings(want)
+	sort.Strings(got)
+	assert.Equal(t, want, got)
+
+	for _, iss := range RunLint(fs, files, Setting{DisabledLintFuncs: []string{"lintUnitAbbreviations", "Counter"}}) {
+		assert.NotContains(t, []string{"UnitAbbreviations", "Counter"}, iss.RuleID)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "./testdata/testdata.go", nil, parser.AllErrors)
+	require.NoError(t, err)
+	files := []*ast.File{file}
+
+	for _, iss := range RunLint(fs, files, Setting{Strict: true}) {
+		r, _ := RuleByID(iss.RuleID)
+		assert.Equal(t, r.Severity, iss.Severity, iss.RuleID)
+	}
+
+	issues := RunLint(fs, files, Setting{
+		Strict: true,
+		Severities: map[string]Severity{
+			"Counter": SeverityInfo,
+			"Help":    SeverityWarning,
+			"Parsing": SeverityError,
+		},
+	})
+	rules := map[string]bool{}
+	for _, iss := range issues {
+		rules[iss.RuleID] = true
+		switch iss.RuleID {
+		case "Counter":
+			assert.Equal(t, SeverityInfo, iss.Severity)
+		case "Help":
+			assert.Equal(t, SeverityWarning, iss.Severity)
+		case "Parsing":
+			assert.Equal(t, SeverityError, iss.Severity)
+		}
+	}
+	assert.True(t, rules["Counter"] && rules["Help"] && rules["Parsing"], "%v", rules)
+
+	s := Setting{Severities: map[string]Severity{"lintUnitAbbreviations": SeverityInfo}}
+	assert.Equal(t, SeverityInfo, s.severity(rulesByID["UnitAbbreviations"]))
+
+	sev, err := ParseSeverity("warning")
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityWarning, sev)
+	_, err = ParseSeverity("fatal")
+	assert.EqualError(t, err, `unknown severity "fatal", must be "error", "warning" or "info"`)
+}
+
+func TestPromlintConstLabels(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "constlabels.go", `package foo
+
+var _ = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name:        "queue_length",
+	Help:        "Length of the queue.",
+	ConstLabels: prometheus.Labels{"le": "x", "queueName": "y"},
+})
+`)
+
+	var texts []string
+	for _, iss := range RunLint(fs, []*ast.File{file}, Setting{DisabledLintFuncs: []string{"WillPanic"}}) {
+		texts = append(texts, iss.Text+" ("+iss.RuleID+")")
+	}
+	assert.Equal(t, []string{
+		`non-histogram metrics should not have "le" label (HistogramSummaryReserved)`,
+		"label names should be written in 'snake_case' not 'camelCase' (CamelCase)",
+	}, texts)
+}
@@ -0,0 +1,153 @@
+package promlinter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultSynonyms are the groups of name tokens meaning the same, used if
+// none are configured. The first token of a group is the canonical one.
+var defaultSynonyms = [][]string{
+	{"duration", "latency"},
+	{"request", "req"},
+	{"response", "resp", "res"},
+	{"error", "err", "failure"},
+	{"connection", "conn"},
+	{"message", "msg"},
+}
+
+// lintNearDuplicates reports metric names which are likely accidental
+// duplicates of another one, e.g. http_request_total next to
+// http_requests_total, or _latency_seconds next to _duration_seconds.
+func (v *visitor) lintNearDuplicates(synonyms [][]string) {
+	if len(synonyms) == 0 {
+		synonyms = defaultSynonyms
+	}
+	canonical := map[string]string{}
+	for _, group := range synonyms {
+		for _, token := range group {
+			canonical[token] = group[0]
+		}
+	}
+
+	type name struct {
+		name   string
+		tokens []string
+		m      *MetricFamilyWithPos
+	}
+	var names []name
+	seen := map[string]bool{}
+	for i := range v.metrics {
+		n := v.metrics[i].MetricFamily.GetName()
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		var tokens []string
+		for _, token := range strings.Split(strings.ToLower(n), "_") {
+			if c, ok := canonical[token]; ok {
+				token = c
+			} else if c, ok := canonical[singular(token)]; ok {
+				token = c
+			}
+			tokens = append(tokens, singular(token))
+		}
+		names = append(names, name{name: n, tokens: tokens, m: &v.metrics[i]})
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].name < names[j].name
+	})
+
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			if !similarTokens(names[i].tokens, names[j].tokens) {
+				continue
+			}
+
+			v.issues = append(v.issues, Issue{
+				Pos:    names[i].m.Pos,
+				Metric: names[i].name,
+				Text: fmt.Sprintf("metric name is similar to %q at %s, they are likely accidental duplicates",
+					names[j].name, names[j].m.Pos),
+			})
+		}
+	}
+}
+
+// similarTokens reports whether the normalized tokens are the same, or
+// differ by a single token inserted, deleted or with a typo.
+func similarTokens(a, b []string) bool {
+	return tokenDistance(a, b) <= 1
+}
+
+// tokenDistance is the edit distance of the tokens, where inserting or
+// deleting a token costs 1, and so does replacing it by a token differing
+// by a typo. Replacing it by another token costs 2, as deleting and
+// inserting it.
+func tokenDistance(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+replaceCost(a[i-1], b[j-1]))
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func replaceCost(x, y string) int {
+	switch {
+	case x == y:
+		return 0
+	case len(x) >= 4 && len(y) >= 4 && levenshtein(x, y) == 1:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func singular(token string) string {
+	if len(token) > 3 && strings.HasSuffix(token, "s") && !strings.HasSuffix(token, "ss") {
+		return strings.TrimSuffix(token, "s")
+	}
+	return token
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
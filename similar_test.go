@@ -0,0 +1,42 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNearDuplicates(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "similar.go", `package foo
+
+var (
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "http_requests_total", Help: "Requests."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "http_request_total", Help: "Requests."})
+	_ = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "rpc_latency_seconds", Help: "Latency."})
+	_ = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "rpc_duration_seconds", Help: "Duration."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "cache_evictons_total", Help: "Evictions."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "cache_evictions_total", Help: "Evictions."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_length", Help: "Queue length."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_depth", Help: "Queue depth."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "api_rpc_calls_total", Help: "Calls."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "rpc_calls_total", Help: "Calls."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "grpc_server_handled_total", Help: "Handled."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "grpc_handled_total", Help: "Handled."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "disk_free_bytes", Help: "Free disk space."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "disk_used_bytes", Help: "Used disk space."})
+)
+`)
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{EnabledLintFuncs: []string{"NearDuplicate"}})
+	texts := issueTexts(issues)
+	assert.Equal(t, []string{
+		`api_rpc_calls_total: metric name is similar to "rpc_calls_total" at similar.go:13:28, they are likely accidental duplicates`,
+		`cache_evictions_total: metric name is similar to "cache_evictons_total" at similar.go:8:28, they are likely accidental duplicates`,
+		`grpc_handled_total: metric name is similar to "grpc_server_handled_total" at similar.go:14:28, they are likely accidental duplicates`,
+		`http_request_total: metric name is similar to "http_requests_total" at similar.go:4:28, they are likely accidental duplicates`,
+		`rpc_duration_seconds: metric name is similar to "rpc_latency_seconds" at similar.go:6:30, they are likely accidental duplicates`,
+	}, texts)
+}
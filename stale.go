@@ -0,0 +1,51 @@
+package promlinter
+
+import (
+	"fmt"
+	"path"
+)
+
+// defaultEntityLabels are the patterns of label names identifying transient
+// entities, used if none are configured.
+var defaultEntityLabels = []string{
+	"pod", "pod_name", "container", "container_name",
+	"connection", "conn", "session", "client", "peer", "remote_addr",
+	"uuid", "*_id",
+}
+
+// lintStaleSeries reports vectors with labels identifying transient
+// entities, whose series are never deleted and leak after the entity is gone.
+func (v *visitor) lintStaleSeries(entityLabels []string) {
+	if len(entityLabels) == 0 {
+		entityLabels = defaultEntityLabels
+	}
+
+	for _, m := range v.metrics {
+		if !m.IsVector() || len(m.Deletes) > 0 || len(m.MetricFamily.Metric) == 0 {
+			continue
+		}
+
+		for _, label := range m.MetricFamily.Metric[0].Label {
+			if label.Value != nil || !matchAny(entityLabels, label.GetName()) {
+				continue
+			}
+
+			v.issues = append(v.issues, Issue{
+				Pos:    m.Pos,
+				Metric: m.MetricFamily.GetName(),
+				Text: fmt.Sprintf("vector with entity label %q never deletes its series, "+
+					"call DeleteLabelValues, Delete or DeletePartialMatch when the entity goes away", label.GetName()),
+			})
+			break
+		}
+	}
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaleSeries(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "stale.go", `package foo
+
+type metrics struct {
+	podRestarts *prometheus.CounterVec
+	connBytes   *prometheus.CounterVec
+	requests    *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		podRestarts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pod_restarts_total",
+			Help: "Restarts of a pod.",
+		}, []string{"namespace", "pod"}),
+		connBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "connection_bytes_total",
+			Help: "Bytes sent on a connection.",
+		}, []string{"connection_id"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "requests_total",
+			Help: "Requests.",
+		}, []string{"method"}),
+	}
+}
+
+func (m *metrics) restart(ns, pod string) {
+	m.podRestarts.WithLabelValues(ns, pod).Inc()
+}
+
+func (m *metrics) send(id string, n int) {
+	c := m.connBytes.WithLabelValues(id)
+	c.Add(float64(n))
+	m.requests.With(prometheus.Labels{"method": "send"}).Inc()
+}
+
+func (m *metrics) close(id string) {
+	m.connBytes.DeleteLabelValues(id)
+}
+`)
+
+	metrics := RunList(fs, []*ast.File{file}, false)
+	if assert.Len(t, metrics, 3) {
+		assert.Equal(t, "pod_restarts_total", metrics[0].MetricFamily.GetName())
+		assert.Len(t, metrics[0].Updates, 1)
+		assert.Empty(t, metrics[0].Deletes)
+
+		assert.Equal(t, "connection_bytes_total", metrics[1].MetricFamily.GetName())
+		assert.Len(t, metrics[1].Updates, 2)
+		assert.Len(t, metrics[1].Deletes, 1)
+		assert.True(t, metrics[1].IsVector())
+	}
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{EnabledLintFuncs: []string{"StaleSeries"}})
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "pod_restarts_total", issues[0].Metric)
+		assert.Equal(t, `vector with entity label "pod" never deletes its series, `+
+			`call DeleteLabelValues, Delete or DeletePartialMatch when the entity goes away`, issues[0].Text)
+	}
+
+	issues = RunLint(fs, []*ast.File{file}, Setting{
+		EnabledLintFuncs: []string{"StaleSeries"},
+		EntityLabels:     []string{"method"},
+	})
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "requests_total", issues[0].Metric)
+	}
+}
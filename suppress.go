@@ -0,0 +1,199 @@
+package promlinter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// suppressionDirective starts the comments suppressing rules for a metric,
+// e.g. //promlinter:ignore Counter reason="legacy dashboards".
+const suppressionDirective = "promlinter:ignore"
+
+// suppressionRule reports the suppressions which are unused or invalid.
+const suppressionRule = "Suppression"
+
+// suppression suppresses the issues of some rules reported on the line of
+// its comment, or on the line below if the comment is on its own line.
+type suppression struct {
+	pos token.Position
+	// trailing is set if the comment follows code on its line.
+	trailing bool
+	// rules are the rule IDs or aliases, as written in the comment.
+	rules  []string
+	reason string
+	// used are the rules which had issues suppressed.
+	used map[string]bool
+}
+
+// collectSuppressions records the suppression comments of the file, which
+// must be parsed with parser.ParseComments.
+func (v *visitor) collectSuppressions(file *ast.File) {
+	n := len(v.suppressions)
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, "//") {
+				continue
+			}
+			text := strings.TrimSpace(c.Text[2:])
+			if !strings.HasPrefix(text, suppressionDirective) {
+				continue
+			}
+			text = text[len(suppressionDirective):]
+			if text != "" && text[0] != ' ' && text[0] != '\t' {
+				continue
+			}
+			v.suppressions = append(v.suppressions, parseSuppression(v.fs.Position(c.Pos()), text))
+		}
+	}
+	if len(v.suppressions) == n {
+		return
+	}
+
+	// A comment is trailing if a node starts or ends before it on its line.
+	suppressions := v.suppressions[n:]
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		for _, pos := range []token.Position{v.fs.Position(node.Pos()), v.fs.Position(node.End())} {
+			for i := range suppressions {
+				if pos.Line == suppressions[i].pos.Line && pos.Column <= suppressions[i].pos.Column {
+					suppressions[i].trailing = true
+				}
+			}
+		}
+		return true
+	})
+}
+
+// parseSuppression parses the text after the directive, the comma or space
+// separated rules, then the reason, quoted or not.
+func parseSuppression(pos token.Position, text string) suppression {
+	s := suppression{pos: pos, used: map[string]bool{}}
+	if i := strings.Index(text, "reason="); i >= 0 {
+		reason := strings.TrimSpace(text[i+len("reason="):])
+		if unquoted, err := strconv.Unquote(reason); err == nil {
+			reason = unquoted
+		}
+		s.reason = strings.TrimSpace(reason)
+		text = text[:i]
+	}
+	s.rules = strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	return s
+}
+
+// on reports whether the suppression is on the line, or on the line above.
+func (s *suppression) on(filename string, line int) bool {
+	return s.pos.Filename == filename && (s.pos.Line == line || !s.trailing && s.pos.Line == line-1)
+}
+
+// suppresses returns the rule, as written in the comment, by which the
+// suppression applies to the issue of the metric, if any. The suppression
+// is on the line of the issue, or of a declaration of the metric, e.g. the
+// NewDesc call of a const metric.
+func (s *suppression) suppresses(iss Issue, m *MetricFamilyWithPos) (string, bool) {
+	on := s.on(iss.Pos.Filename, iss.Pos.Line)
+	if !on && m != nil {
+		for _, d := range m.declarations {
+			if s.on(d.filename, d.lines.Start) {
+				on = true
+				break
+			}
+		}
+	}
+	if !on {
+		return "", false
+	}
+
+	for _, name := range s.rules {
+		if ruleID(name) == iss.RuleID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// suppress removes the suppressed issues, and records the suppressions used.
+func (v *visitor) suppress(issues []Issue) []Issue {
+	kept := issues[:0]
+	for _, iss := range issues {
+		m := v.issueMetric(iss)
+		suppressed := false
+		for i := range v.suppressions {
+			if name, ok := v.suppressions[i].suppresses(iss, m); ok {
+				v.suppressions[i].used[name] = true
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, iss)
+		}
+	}
+	return kept
+}
+
+// issueMetric returns the metric the issue is reported on, the one with its
+// name declared at its position, if any.
+func (v *visitor) issueMetric(iss Issue) *MetricFamilyWithPos {
+	if iss.Metric == "" {
+		return nil
+	}
+	var m *MetricFamilyWithPos
+	first := -1
+	for _, i := range v.byPos[iss.Pos] {
+		if v.metrics[i].MetricFamily.GetName() == iss.Metric && (first < 0 || i < first) {
+			m, first = &v.metrics[i], i
+		}
+	}
+	return m
+}
+
+// lintSuppressions reports the suppressions without a reason, naming no or
+// unknown rules, or naming rules which ran but had nothing to suppress.
+func (v *visitor) lintSuppressions(ran map[string]bool) {
+	for _, s := range v.suppressions {
+		var texts []string
+		if len(s.rules) == 0 {
+			texts = append(texts, "suppression names no rule")
+		}
+		for _, name := range s.rules {
+			if _, ok := RuleByID(name); !ok {
+				texts = append(texts, fmt.Sprintf("suppression names unknown rule %q", name))
+			} else if ran[ruleID(name)] && !s.used[name] {
+				texts = append(texts, fmt.Sprintf("suppression of %s is unused", name))
+			}
+		}
+		if s.reason == "" {
+			texts = append(texts, "suppression has no reason")
+		}
+
+		metric := v.metricAt(s)
+		for _, text := range texts {
+			v.issues = append(v.issues, Issue{Pos: s.pos, Metric: metric, Text: text})
+		}
+	}
+}
+
+// metricAt returns the name of the metric the suppression applies to, if any.
+func (v *visitor) metricAt(s suppression) string {
+	for _, m := range v.metrics {
+		for _, p := range m.Positions {
+			if s.on(p.Filename, p.Line) {
+				return m.MetricFamily.GetName()
+			}
+		}
+		for _, d := range m.declarations {
+			if s.on(d.filename, d.lines.Start) {
+				return m.MetricFamily.GetName()
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuppressions(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "suppress.go", `package foo
+
+var (
+	//promlinter:ignore Counter reason="legacy dashboards"
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "legacy_requests", Help: "Requests."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "legacy_errors"}) //promlinter:ignore Counter,Help reason="legacy dashboards"
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "legacy_retries", Help: "Retries."}) // promlinter:ignore lintUnitAbbreviations Counter reason=legacy
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "bytes_read", Help: "Bytes read."})
+	//promlinter:ignore Counter
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "pages_read", Help: "Pages read."})
+	//promlinter:ignore Unknown reason="typo"
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "pages_written_total", Help: "Pages written."})
+	//promlinter:ignore reason="no rule"
+
+	//promlinter:ignored Counter
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "files_read", Help: "Files read."})
+)
+`)
+
+	var texts []string
+	for _, iss := range RunLint(fs, []*ast.File{file}, Setting{}) {
+		texts = append(texts, iss.Pos.String()+" "+iss.Metric+": "+iss.Text+" ("+iss.RuleID+")")
+	}
+	assert.Equal(t, []string{
+		`suppress.go:8:28 bytes_read: counter metrics should have "_total" suffix (Counter)`,
+		`suppress.go:16:28 files_read: counter metrics should have "_total" suffix (Counter)`,
+		"suppress.go:7:94 legacy_retries: suppression of lintUnitAbbreviations is unused (Suppression)",
+		"suppress.go:9:2 pages_read: suppression has no reason (Suppression)",
+		`suppress.go:11:2 pages_written_total: suppression names unknown rule "Unknown" (Suppression)`,
+		"suppress.go:13:2 : suppression names no rule (Suppression)",
+	}, texts)
+
+	// The suppressions of rules which didn't run aren't reported unused.
+	for _, iss := range RunLint(fs, []*ast.File{file}, Setting{DisabledLintFuncs: []string{"Counter", "UnitAbbreviations"}}) {
+		assert.NotContains(t, iss.Text, "unused")
+	}
+
+	for _, iss := range RunLint(fs, []*ast.File{file}, Setting{DisabledLintFuncs: []string{"Suppression"}}) {
+		assert.NotEqual(t, "Suppression", iss.RuleID)
+	}
+}
+
+func TestSuppressionsOnNewDesc(t *testing.T) {
+	fs := token.NewFileSet()
+	descs := parseSource(t, fs, "descs.go", `package foo
+
+var pkgDesc = prometheus.NewDesc("pkg_requests", "Requests.", nil, nil) //promlinter:ignore Counter reason="legacy dashboards"
+
+type collector struct {
+	desc  *prometheus.Desc
+	other *prometheus.Desc
+}
+
+func newCollector() *collector {
+	return &collector{
+		//promlinter:ignore Counter reason="legacy dashboards"
+		desc:  prometheus.NewDesc("legacy_requests", "Requests.", nil, nil),
+		other: prometheus.NewDesc("other_requests", "Requests.", nil, nil),
+	}
+}
+`)
+	collect := parseSource(t, fs, "collect.go", `package foo
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, 1)
+	ch <- prometheus.MustNewConstMetric(c.other, prometheus.CounterValue, 1)
+	ch <- prometheus.MustNewConstMetric(pkgDesc, prometheus.CounterValue, 1)
+}
+`)
+
+	var texts []string
+	for _, iss := range RunLint(fs, []*ast.File{descs, collect}, Setting{}) {
+		texts = append(texts, iss.Pos.String()+" "+iss.Metric+": "+iss.Text+" ("+iss.RuleID+")")
+	}
+	assert.Equal(t, []string{
+		`collect.go:5:8 other_requests: counter metrics should have "_total" suffix (Counter)`,
+	}, texts)
+}
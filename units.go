@@ -0,0 +1,177 @@
+package promlinter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// unit is a unit of measurement, metric name suffixes and help text words
+// are compared by dimension.
+type unit struct {
+	name      string
+	dimension string
+}
+
+// unitWords maps the words naming a unit, in metric names or help texts,
+// to the unit.
+var unitWords = map[string]unit{}
+
+func init() {
+	for _, u := range []struct {
+		unit
+		words []string
+	}{
+		{unit{"days", "time"}, []string{"day", "days"}},
+		{unit{"hours", "time"}, []string{"hour", "hours", "hr", "hrs"}},
+		{unit{"minutes", "time"}, []string{"minute", "minutes", "mins"}},
+		{unit{"seconds", "time"}, []string{"second", "seconds", "sec", "secs"}},
+		{unit{"milliseconds", "time"}, []string{"millisecond", "milliseconds", "millis", "ms", "msec", "msecs"}},
+		{unit{"microseconds", "time"}, []string{"microsecond", "microseconds", "micros", "µs", "usec", "usecs"}},
+		{unit{"nanoseconds", "time"}, []string{"nanosecond", "nanoseconds", "nanos", "ns", "nsec", "nsecs"}},
+		{unit{"bits", "size"}, []string{"bit", "bits"}},
+		{unit{"bytes", "size"}, []string{"byte", "bytes", "octet", "octets"}},
+		{unit{"kilobytes", "size"}, []string{"kilobyte", "kilobytes", "kb", "kib", "kibibytes"}},
+		{unit{"megabytes", "size"}, []string{"megabyte", "megabytes", "mb", "mib", "mebibytes"}},
+		{unit{"gigabytes", "size"}, []string{"gigabyte", "gigabytes", "gb", "gib", "gibibytes"}},
+		{unit{"ratio", "fraction"}, []string{"ratio", "fraction"}},
+		{unit{"percent", "fraction"}, []string{"percent", "percentage", "percents", "%"}},
+		{unit{"celsius", "temperature"}, []string{"celsius"}},
+		{unit{"fahrenheit", "temperature"}, []string{"fahrenheit"}},
+		{unit{"kelvin", "temperature"}, []string{"kelvin", "kelvins"}},
+	} {
+		for _, word := range u.words {
+			unitWords[word] = u.unit
+		}
+	}
+}
+
+// nameSuffixes are appended to the unit of a metric name.
+var nameSuffixes = map[string]bool{
+	"total":  true,
+	"count":  true,
+	"sum":    true,
+	"bucket": true,
+}
+
+// nameUnit returns the unit suffix of the metric name, e.g. "seconds" for
+// http_request_duration_seconds_total, or an empty string.
+func nameUnit(name string) string {
+	tokens := strings.Split(name, "_")
+	for len(tokens) > 1 && nameSuffixes[tokens[len(tokens)-1]] {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens[len(tokens)-1]
+}
+
+// helpUnits returns the units mentioned in the help text, in order.
+func helpUnits(help string) []unit {
+	var units []unit
+	words := strings.FieldsFunc(strings.ToLower(help), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%'
+	})
+	for _, word := range words {
+		// "10ms", "99.9%"
+		word = strings.TrimLeftFunc(word, unicode.IsDigit)
+		if u, ok := unitWords[word]; ok {
+			units = append(units, u)
+		}
+	}
+	return units
+}
+
+// lintHelpUnits reports help texts mentioning a unit contradicting the
+// unit suffix of the metric name, e.g. "in milliseconds" for a metric named
+// *_seconds. Units of other dimensions, like "per second" for a *_bytes
+// metric, are ignored.
+func (v *visitor) lintHelpUnits() {
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		want, ok := unitWords[nameUnit(m.MetricFamily.GetName())]
+		if !ok {
+			continue
+		}
+
+		var contradicting []string
+		for _, u := range helpUnits(m.MetricFamily.GetHelp()) {
+			if u.name == want.name {
+				contradicting = nil
+				break
+			}
+			if u.dimension == want.dimension && !contains(contradicting, u.name) {
+				contradicting = append(contradicting, u.name)
+			}
+		}
+		if len(contradicting) == 0 {
+			continue
+		}
+
+		v.issues = append(v.issues, Issue{
+			Pos:    m.Pos,
+			Metric: m.MetricFamily.GetName(),
+			Text: fmt.Sprintf("help text mentions %s but the metric name has unit %s",
+				strings.Join(contradicting, ", "), want.name),
+		})
+	}
+}
+
+// defaultUnitReplacements are the preferred replacements of units, used
+// when none are configured.
+var defaultUnitReplacements = map[string]string{
+	"octets":     "bytes",
+	"percent":    "ratio",
+	"percentage": "ratio",
+}
+
+// lintUnitPolicy reports metric names whose unit suffix breaks the unit
+// policy, and ratios set to a constant outside of 0-1.
+func (v *visitor) lintUnitPolicy(policy UnitSetting) {
+	replacements := policy.Replacements
+	if replacements == nil {
+		replacements = defaultUnitReplacements
+	}
+
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		name := m.MetricFamily.GetName()
+		u := nameUnit(name)
+
+		var text string
+		if r, ok := replacements[u]; ok {
+			text = fmt.Sprintf("metric unit %q should be %q", u, r)
+		} else if contains(policy.Forbidden, u) {
+			text = fmt.Sprintf("metric unit %q is forbidden", u)
+		} else if _, isUnit := unitWords[u]; isUnit && len(policy.Allowed) > 0 && !contains(policy.Allowed, u) {
+			text = fmt.Sprintf("metric unit %q is not allowed, use one of %s", u, strings.Join(policy.Allowed, ", "))
+		}
+		if text == "" {
+			continue
+		}
+
+		v.issues = append(v.issues, Issue{
+			Pos:    m.Pos,
+			Metric: name,
+			Text:   text,
+		})
+	}
+
+	for _, c := range v.calls {
+		if c.method != "Set" || len(c.args) != 1 {
+			continue
+		}
+		f, ok := parseFloat(c.args[0])
+		if !ok || (f >= 0 && f <= 1) {
+			continue
+		}
+		m, _ := v.resolve(c)
+		if m == nil || nameUnit(m.MetricFamily.GetName()) != "ratio" {
+			continue
+		}
+
+		v.issues = append(v.issues, Issue{
+			Pos:    c.pos,
+			Metric: m.MetricFamily.GetName(),
+			Text:   fmt.Sprintf("ratio is set to %v, ratios must be between 0 and 1", f),
+		})
+	}
+}
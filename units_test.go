@@ -0,0 +1,92 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelpUnits(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "units.go", `package foo
+
+var (
+	_ = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "request_duration_seconds", Help: "Request latency in milliseconds."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "sent_bits_total", Help: "Number of bytes sent, in bytes per second."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "cache_hit_ratio", Help: "Percentage of cache hits, e.g. 99.9%."})
+	_ = prometheus.NewCounter(prometheus.CounterOpts{Name: "received_bytes_total", Help: "Bytes received per second."})
+	_ = prometheus.NewGauge(prometheus.GaugeOpts{Name: "timeout_seconds", Help: "Timeout in seconds, 500ms by default."})
+)
+`)
+
+	issues := RunLint(fs, []*ast.File{file}, Setting{
+		DisabledLintFuncs: []string{"MetricUnits"},
+		EnabledLintFuncs:  []string{"HelpUnit"},
+	})
+	texts := issueTexts(issues)
+	assert.Equal(t, []string{
+		"request_duration_seconds: help text mentions milliseconds but the metric name has unit seconds",
+		"sent_bits_total: help text mentions bytes but the metric name has unit bits",
+		"cache_hit_ratio: help text mentions percent but the metric name has unit ratio",
+	}, texts)
+}
+
+func TestNameUnit(t *testing.T) {
+	for name, want := range map[string]string{
+		"request_duration_seconds":        "seconds",
+		"request_duration_seconds_bucket": "seconds",
+		"sent_bytes_total":                "bytes",
+		"requests_total":                  "requests",
+		"total":                           "total",
+	} {
+		assert.Equal(t, want, nameUnit(name), name)
+	}
+}
+
+func TestUnitPolicy(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "units.go", `package foo
+
+var (
+	received = prometheus.NewCounter(prometheus.CounterOpts{Name: "received_octets_total", Help: "Received octets."})
+	hits     = prometheus.NewGauge(prometheus.GaugeOpts{Name: "cache_hit_ratio", Help: "Cache hit ratio."})
+	usage    = prometheus.NewGauge(prometheus.GaugeOpts{Name: "disk_usage_percent", Help: "Disk usage."})
+	temp     = prometheus.NewGauge(prometheus.GaugeOpts{Name: "cpu_temperature_celsius", Help: "CPU temperature."})
+	uptime   = prometheus.NewGauge(prometheus.GaugeOpts{Name: "uptime_hours", Help: "Uptime."})
+)
+
+func update() {
+	hits.Set(0.5)
+	hits.Set(50)
+}
+`)
+	files := []*ast.File{file}
+
+	lint := func(s Setting) []string {
+		return issueTexts(RunLint(fs, files, s))
+	}
+
+	s := Setting{
+		DisabledLintFuncs: []string{"MetricUnits"},
+		EnabledLintFuncs:  []string{"UnitPolicy"},
+	}
+	assert.Equal(t, []string{
+		`received_octets_total: metric unit "octets" should be "bytes"`,
+		`disk_usage_percent: metric unit "percent" should be "ratio"`,
+		"cache_hit_ratio: ratio is set to 50, ratios must be between 0 and 1",
+	}, lint(s))
+
+	s.Units = UnitSetting{
+		Allowed:      []string{"seconds", "bytes", "ratio", "celsius"},
+		Forbidden:    []string{"percent"},
+		Replacements: map[string]string{"octets": "bytes"},
+	}
+	assert.Equal(t, []string{
+		`received_octets_total: metric unit "octets" should be "bytes"`,
+		`disk_usage_percent: metric unit "percent" is forbidden`,
+		`uptime_hours: metric unit "hours" is not allowed, use one of seconds, bytes, ratio, celsius`,
+		"cache_hit_ratio: ratio is set to 50, ratios must be between 0 and 1",
+	}, lint(s))
+}
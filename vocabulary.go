@@ -0,0 +1,85 @@
+package promlinter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelNames returns the names of the variable and const labels of the metric.
+func (m *MetricFamilyWithPos) labelNames() []string {
+	var names []string
+	if len(m.MetricFamily.Metric) > 0 {
+		for _, label := range m.MetricFamily.Metric[0].Label {
+			names = append(names, strings.Trim(label.GetName(), `"`))
+		}
+	}
+	return names
+}
+
+// lintLabelVocabulary reports labels named by a synonym of the canonical
+// name in the vocabulary, and labels written in different forms across
+// metrics, e.g. status_code and statusCode.
+func (v *visitor) lintLabelVocabulary(vocabulary map[string][]string) {
+	canonical := map[string]string{}
+	for name, synonyms := range vocabulary {
+		for _, synonym := range synonyms {
+			canonical[synonym] = name
+		}
+	}
+
+	// forms counts the metrics using each form of a normalized label name.
+	forms := map[string]map[string]int{}
+	for i := range v.metrics {
+		for _, name := range v.metrics[i].labelNames() {
+			key := normalizeLabelName(name)
+			if forms[key] == nil {
+				forms[key] = map[string]int{}
+			}
+			forms[key][name]++
+		}
+	}
+
+	for i := range v.metrics {
+		m := &v.metrics[i]
+		for _, name := range m.labelNames() {
+			if c, ok := canonical[name]; ok {
+				v.issues = append(v.issues, Issue{
+					Pos:    m.Pos,
+					Metric: m.MetricFamily.GetName(),
+					Text:   fmt.Sprintf("label %q should be named %q", name, c),
+				})
+				continue
+			}
+
+			if preferred := preferredForm(forms[normalizeLabelName(name)]); preferred != name {
+				v.issues = append(v.issues, Issue{
+					Pos:    m.Pos,
+					Metric: m.MetricFamily.GetName(),
+					Text: fmt.Sprintf("label %q is written as %q by %d other metrics",
+						name, preferred, forms[normalizeLabelName(name)][preferred]),
+				})
+			}
+		}
+	}
+}
+
+func normalizeLabelName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// preferredForm returns the form used by most metrics, or the first one in
+// lexical order for a tie.
+func preferredForm(forms map[string]int) string {
+	names := make([]string, 0, len(forms))
+	for name := range forms {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if forms[names[i]] != forms[names[j]] {
+			return forms[names[i]] > forms[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names[0]
+}
@@ -0,0 +1,41 @@
+package promlinter
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelVocabulary(t *testing.T) {
+	fs := token.NewFileSet()
+	file := parseSource(t, fs, "vocabulary.go", `package foo
+
+var (
+	_ = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "http_requests_total", Help: "Requests."}, []string{"status_code"})
+	_ = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "grpc_requests_total", Help: "Requests."}, []string{"status_code"})
+	_ = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "rpc_requests_total", Help: "Requests."}, []string{"statusCode"})
+	_ = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "api_requests_total", Help: "Requests."}, []string{"code"})
+)
+`)
+	files := []*ast.File{file}
+
+	issues := RunLint(fs, files, Setting{EnabledLintFuncs: []string{"LabelVocabulary"}, DisabledLintFuncs: []string{"CamelCase"}})
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "rpc_requests_total", issues[0].Metric)
+		assert.Equal(t, `label "statusCode" is written as "status_code" by 2 other metrics`, issues[0].Text)
+	}
+
+	issues = RunLint(fs, files, Setting{
+		EnabledLintFuncs:  []string{"LabelVocabulary"},
+		DisabledLintFuncs: []string{"CamelCase"},
+		LabelVocabulary:   map[string][]string{"code": {"status_code", "statusCode"}},
+	})
+	texts := issueTexts(issues)
+	assert.Equal(t, []string{
+		`http_requests_total: label "status_code" should be named "code"`,
+		`grpc_requests_total: label "status_code" should be named "code"`,
+		`rpc_requests_total: label "statusCode" should be named "code"`,
+	}, texts)
+}